@@ -49,8 +49,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"runtime"
+	"time"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
 )
 
+// JudgeLimits는 테스트케이스 하나를 실행할 때의 제한입니다
+type JudgeLimits struct {
+	TimeLimitMs     int `json:"time_limit_ms"`
+	MemoryLimitMB   int `json:"memory_limit_mb"`
+	OutputLineLimit int `json:"output_line_limit"`
+	OutputByteLimit int `json:"output_byte_limit"`
+}
+
 type Config struct {
 	Database struct {
 		Username string `json:"username"`
@@ -63,6 +75,13 @@ type Config struct {
 	JWT struct {
 		SecretKey string `json:"secret_key"`
 	} `json:"jwt"`
+	Judge struct {
+		Workers   int `json:"workers"`    // 서버 전체에서 동시에 실행되는 VM 수
+		QueueSize int `json:"queue_size"` // 대기 중이거나 실행 중인 제출 수 상한
+		// 문제에 제한을 지정하지 않았을 때 쓰는 값과, 문제에 지정할 수 있는 최댓값
+		Limits    JudgeLimits `json:"limits"`
+		MaxLimits JudgeLimits `json:"max_limits"`
+	} `json:"judge"`
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -82,6 +101,32 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("JWT secret key is required in config file")
 	}
 
+	// Judge defaults
+	if config.Judge.Workers <= 0 {
+		config.Judge.Workers = runtime.NumCPU()
+	}
+	if config.Judge.QueueSize <= 0 {
+		config.Judge.QueueSize = 100
+	}
+	// 메모리 제한이 있는 VM은 다른 VM 없이 혼자 실행되므로, 메모리 제한은 기본값으로
+	// 두지 않고 필요한 문제에만 지정한다
+	config.Judge.Limits.fill(JudgeLimits{
+		TimeLimitMs:     2000,
+		MemoryLimitMB:   0,
+		OutputLineLimit: 1000,
+		OutputByteLimit: 64 * 1024,
+	})
+	config.Judge.MaxLimits.fill(JudgeLimits{
+		TimeLimitMs:     10000,
+		MemoryLimitMB:   512,
+		OutputLineLimit: 100000,
+		OutputByteLimit: 16 << 20,
+	})
+	if l, m := config.Judge.Limits, config.Judge.MaxLimits; l.TimeLimitMs > m.TimeLimitMs ||
+		l.MemoryLimitMB > m.MemoryLimitMB || l.OutputLineLimit > m.OutputLineLimit || l.OutputByteLimit > m.OutputByteLimit {
+		return nil, fmt.Errorf("judge limits must not exceed judge max_limits")
+	}
+
 	return &config, nil
 }
 
@@ -99,3 +144,46 @@ func (c *Config) GetDSN() string {
 func (c *Config) GetJWTSecret() []byte {
 	return []byte(c.JWT.SecretKey)
 }
+
+func (c *Config) GetJudgeWorkers() int {
+	return c.Judge.Workers
+}
+
+func (c *Config) GetJudgeQueueSize() int {
+	return c.Judge.QueueSize
+}
+
+// GetJudgeLimits는 문제에 제한이 없을 때 쓰는 채점 제한입니다
+func (c *Config) GetJudgeLimits() judge.Limits {
+	return c.Judge.Limits.limits()
+}
+
+// GetJudgeMaxLimits는 문제에 지정할 수 있는 가장 큰 채점 제한입니다
+func (c *Config) GetJudgeMaxLimits() judge.Limits {
+	return c.Judge.MaxLimits.limits()
+}
+
+// fill은 0 이하인 항목을 defaults의 값으로 채웁니다
+func (l *JudgeLimits) fill(defaults JudgeLimits) {
+	if l.TimeLimitMs <= 0 {
+		l.TimeLimitMs = defaults.TimeLimitMs
+	}
+	if l.MemoryLimitMB <= 0 {
+		l.MemoryLimitMB = defaults.MemoryLimitMB
+	}
+	if l.OutputLineLimit <= 0 {
+		l.OutputLineLimit = defaults.OutputLineLimit
+	}
+	if l.OutputByteLimit <= 0 {
+		l.OutputByteLimit = defaults.OutputByteLimit
+	}
+}
+
+func (l JudgeLimits) limits() judge.Limits {
+	return judge.Limits{
+		Timeout:     time.Duration(l.TimeLimitMs) * time.Millisecond,
+		MemoryBytes: uint64(l.MemoryLimitMB) << 20,
+		OutputLines: l.OutputLineLimit,
+		OutputBytes: l.OutputByteLimit,
+	}
+}
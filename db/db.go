@@ -14,10 +14,49 @@ func InitDB(dsn string) (*gorm.DB, error) {
 	}
 
 	// 모델 마이그레이션
-	err = db.AutoMigrate(&models.Class{}, &models.Problem{}, &models.User{}, &models.Solved{})
+	err = db.AutoMigrate(&models.Class{}, &models.Problem{}, &models.Testcase{}, &models.User{}, &models.Solved{}, &models.Submission{}, &models.BestScore{})
+	if err != nil {
+		return nil, err
+	}
+
+	// 기존 문자열 컬럼의 테스트케이스 이전
+	if err := migrateLegacyTestcases(db); err != nil {
+		return nil, err
+	}
+
+	// 서버가 내려가면서 채점이 끊긴 제출 정리
+	err = db.Model(&models.Submission{}).
+		Where("status IN ?", []string{models.SubmissionQueued, models.SubmissionRunning}).
+		Update("status", models.SubmissionCanceled).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
+
+// migrateLegacyTestcases는 TestcaseInput/TestcaseOutput 컬럼에만 테스트케이스가 있는 문제를
+// testcases 테이블로 옮깁니다. 이미 테스트케이스가 등록된 문제는 건너뜁니다.
+func migrateLegacyTestcases(db *gorm.DB) error {
+	var problems []models.Problem
+	err := db.Where("testcase_input <> '' AND NOT EXISTS (SELECT 1 FROM testcases WHERE testcases.problem_id = problems.id)").
+		Find(&problems).Error
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range problems {
+		testcases := models.ParseLegacyTestcases(problem.TestcaseInput, problem.TestcaseOutput)
+		if len(testcases) == 0 {
+			continue
+		}
+		for i := range testcases {
+			testcases[i].ProblemID = problem.ID
+		}
+		if err := db.Create(&testcases).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
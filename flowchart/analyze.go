@@ -0,0 +1,115 @@
+// flowchart/analyze.go
+package flowchart
+
+import (
+	"errors"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+)
+
+// Analyze는 구조 제한을 확인할 수 있도록 순서도의 모양을 읽습니다. 판단 블록은
+// 컴파일러와 같이 구조화한 결과에서 while이 되면 반복(loop), if가 되면 조건(if)으로
+// 셉니다. if/while로 나타낼 수 없는 순서도는 되돌아가는 간선이 나가거나 들어오는
+// 판단 블록을 반복으로 세고, 중첩 깊이를 -1로 둡니다.
+func Analyze(g *Graph) (*judge.Structure, error) {
+	p, err := load(g)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &judge.Structure{Blocks: make(map[string]int)}
+	for _, n := range g.Nodes {
+		switch n.Type {
+		case Input:
+			s.Blocks[judge.BlockInput]++
+		case Output:
+			s.Blocks[judge.BlockOutput]++
+		case Process:
+			s.Blocks[judge.BlockProcess]++
+		case Decision:
+			s.Blocks[judge.BlockDecision]++
+		}
+	}
+
+	stmts, err := structure(p)
+	switch {
+	case err == nil:
+		countBranches(stmts, s.Blocks)
+		s.Depth = nestingDepth(stmts)
+	case errors.Is(err, errUnstructured):
+		loops := backEdgeDecisions(p)
+		for _, n := range g.Nodes {
+			if n.Type != Decision {
+				continue
+			}
+			if loops[n.ID] {
+				s.Blocks[judge.BlockLoop]++
+			} else {
+				s.Blocks[judge.BlockIf]++
+			}
+		}
+		s.Depth = -1
+	default:
+		return nil, err
+	}
+	return s, nil
+}
+
+// countBranches는 구조화한 순서도의 반복과 조건을 셉니다
+func countBranches(stmts []stmt, blocks map[string]int) {
+	for _, st := range stmts {
+		switch st := st.(type) {
+		case loopStmt:
+			blocks[judge.BlockLoop]++
+			countBranches(st.body, blocks)
+		case ifStmt:
+			blocks[judge.BlockIf]++
+			countBranches(st.then, blocks)
+			countBranches(st.els, blocks)
+		}
+	}
+}
+
+// backEdgeDecisions는 시작 블록에서 깊이 우선으로 따라갈 때 되돌아가는 간선
+// (지금 따라가는 경로 위의 블록으로 가는 간선)이 나가거나 들어오는 판단 블록입니다
+func backEdgeDecisions(p *program) map[string]bool {
+	loops := make(map[string]bool)
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for _, e := range p.idx.outgoing[id] {
+			switch state[e.To] {
+			case visiting:
+				for _, end := range []string{e.From, e.To} {
+					if p.node(end).Type == Decision {
+						loops[end] = true
+					}
+				}
+			case 0:
+				visit(e.To)
+			}
+		}
+		state[id] = done
+	}
+	visit(p.start)
+	return loops
+}
+
+// nestingDepth는 구조화한 순서도에서 반복/조건이 겹친 가장 깊은 단계입니다
+func nestingDepth(stmts []stmt) int {
+	depth := 0
+	for _, st := range stmts {
+		switch st := st.(type) {
+		case loopStmt:
+			depth = max(depth, 1+nestingDepth(st.body))
+		case ifStmt:
+			depth = max(depth, 1+max(nestingDepth(st.then), nestingDepth(st.els)))
+		}
+	}
+	return depth
+}
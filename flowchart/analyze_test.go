@@ -0,0 +1,65 @@
+package flowchart
+
+import (
+	"testing"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+)
+
+// evenGraph는 0부터 n-1까지 반복하며 짝수만 출력합니다 (반복 안의 조건)
+func evenGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "in", Type: Input, Var: "n"},
+			{ID: "init", Type: Process, Expr: "i = 0"},
+			{ID: "loop", Type: Decision, Expr: "i < n"},
+			{ID: "even", Type: Decision, Expr: "i % 2 == 0"},
+			{ID: "out", Type: Output, Expr: "i"},
+			{ID: "inc", Type: Process, Expr: "i += 1"},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "in"},
+			{From: "in", To: "init"},
+			{From: "init", To: "loop"},
+			{From: "loop", To: "even", Label: BranchYes},
+			{From: "loop", To: "e", Label: BranchNo},
+			{From: "even", To: "out", Label: BranchYes},
+			{From: "even", To: "inc", Label: BranchNo},
+			{From: "out", To: "inc"},
+			{From: "inc", To: "loop"},
+		},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name   string
+		graph  *Graph
+		blocks map[string]int
+		depth  int
+	}{
+		{"loop", sumGraph(), map[string]int{judge.BlockInput: 1, judge.BlockProcess: 2, judge.BlockOutput: 1, judge.BlockDecision: 1, judge.BlockLoop: 1}, 1},
+		{"nested if", signGraph(), map[string]int{judge.BlockInput: 1, judge.BlockOutput: 4, judge.BlockDecision: 2, judge.BlockIf: 2}, 2},
+		{"if inside loop", evenGraph(), map[string]int{judge.BlockInput: 1, judge.BlockProcess: 2, judge.BlockOutput: 1, judge.BlockDecision: 2, judge.BlockLoop: 1, judge.BlockIf: 1}, 2},
+		{"unstructured", countdownGraph(), map[string]int{judge.BlockInput: 1, judge.BlockOutput: 1, judge.BlockProcess: 1, judge.BlockDecision: 1, judge.BlockLoop: 1}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Analyze(tt.graph)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for block, n := range tt.blocks {
+				if s.Blocks[block] != n {
+					t.Errorf("%s blocks = %d, want %d", block, s.Blocks[block], n)
+				}
+			}
+			if s.Depth != tt.depth {
+				t.Errorf("depth = %d, want %d", s.Depth, tt.depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// flowchart/compile.go
+package flowchart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prelude는 생성된 코드가 쓰는 도우미 함수입니다. 변수 이름은 "__"로 시작할 수
+// 없으므로 사용자 변수와 겹치지 않습니다.
+const prelude = `function __get(v, name, node) {
+  if (v === undefined) throw new Error("블록 " + node + ": 변수 " + name + "에 값이 없습니다");
+  return v;
+}
+function __div(a, b, node) {
+  if (b === 0) throw new Error("블록 " + node + ": 0으로 나눌 수 없습니다");
+  return a / b;
+}
+function __mod(a, b, node) {
+  if (b === 0) throw new Error("블록 " + node + ": 0으로 나눌 수 없습니다");
+  return a % b;
+}
+function __input() {
+  var s = String(prompt());
+  var t = s.trim();
+  return t !== "" && !isNaN(Number(t)) ? Number(t) : s;
+}
+`
+
+// Compile은 순서도를 채점기에서 실행할 JavaScript 코드로 바꿉니다. 입력 블록은
+// prompt(), 출력 블록은 console.log(줄바꿈 없이는 print)가 되고, 판단 블록은
+// 모양에 따라 if/else나 while 문이 됩니다. if/while만으로 나타낼 수 없는 순서도는 블록 사이를 옮겨
+// 다니는 상태 기계로 바꿉니다.
+func Compile(g *Graph) (string, error) {
+	p, err := load(g)
+	if err != nil {
+		return "", err
+	}
+
+	e := &emitter{prog: p}
+	e.line("(function () {")
+	e.depth++
+	if len(p.vars) > 0 {
+		e.line("var " + strings.Join(p.vars, ", ") + ";")
+	}
+
+	stmts, err := structure(p)
+	switch {
+	case err == nil:
+		e.stmts(stmts)
+	case errors.Is(err, errUnstructured):
+		e.stateMachine()
+	default:
+		return "", err
+	}
+
+	e.depth--
+	e.line("})();")
+	return prelude + e.sb.String(), nil
+}
+
+type emitter struct {
+	prog  *program
+	sb    strings.Builder
+	depth int
+}
+
+func (e *emitter) line(s string) {
+	e.sb.WriteString(strings.Repeat("  ", e.depth))
+	e.sb.WriteString(s)
+	e.sb.WriteByte('\n')
+}
+
+func (e *emitter) stmts(stmts []stmt) {
+	for _, s := range stmts {
+		switch s := s.(type) {
+		case nodeStmt:
+			e.node(s.id)
+		case endStmt:
+			e.line("return;")
+		case loopStmt:
+			cond := e.expr(s.id, e.prog.exprs[s.id])
+			if s.negate {
+				cond = "!" + cond
+			}
+			e.block("while ("+cond+") {", s.body)
+			e.line("}")
+		case ifStmt:
+			e.block("if ("+e.expr(s.id, e.prog.exprs[s.id])+") {", s.then)
+			if s.hasElse {
+				e.block("} else {", s.els)
+			}
+			e.line("}")
+		}
+	}
+}
+
+func (e *emitter) block(head string, body []stmt) {
+	e.line(head)
+	e.depth++
+	e.stmts(body)
+	e.depth--
+}
+
+// node는 process, input, output 블록 하나의 코드를 씁니다
+func (e *emitter) node(id string) {
+	n := e.prog.node(id)
+	switch n.Type {
+	case Input:
+		e.line(n.Var + " = __input();")
+	case Output:
+		fn := "console.log"
+		if n.NoNewline {
+			fn = "print"
+		}
+		e.line(fn + "(" + e.expr(id, e.prog.exprs[id]) + ");")
+	case Process:
+		for _, a := range e.prog.assigns[id] {
+			e.line(a.name + " = " + e.assignValue(id, a) + ";")
+		}
+	}
+}
+
+func (e *emitter) assignValue(id string, a assignment) string {
+	if a.op == "=" {
+		return e.expr(id, a.value)
+	}
+	op := strings.TrimSuffix(a.op, "=")
+	return e.expr(id, binaryExpr{op: op, x: ident{a.name}, y: a.value})
+}
+
+// stateMachine은 __pc에 현재 블록 ID를 두고 switch로 블록을 하나씩 실행합니다
+func (e *emitter) stateMachine() {
+	p := e.prog
+	e.line("var __pc = " + quote(p.start) + ";")
+	e.line("while (true) {")
+	e.depth++
+	e.line("switch (__pc) {")
+	for _, n := range p.graph.Nodes {
+		e.line("case " + quote(n.ID) + ":")
+		e.depth++
+		switch n.Type {
+		case End:
+			e.line("return;")
+			e.depth--
+			continue
+		case Decision:
+			yes, no := p.idx.branches(n.ID)
+			e.line("__pc = " + e.expr(n.ID, p.exprs[n.ID]) + " ? " + quote(yes) + " : " + quote(no) + ";")
+		case Start:
+			e.line("__pc = " + quote(p.idx.next(n.ID)) + ";")
+		default:
+			e.node(n.ID)
+			e.line("__pc = " + quote(p.idx.next(n.ID)) + ";")
+		}
+		e.line("break;")
+		e.depth--
+	}
+	e.line("}")
+	e.depth--
+	e.line("}")
+}
+
+// expr은 식을 JavaScript 코드로 바꿉니다. 우선순위를 따지지 않도록 연산마다
+// 괄호로 감쌉니다. id는 에러 메시지에 쓸 블록 ID입니다.
+func (e *emitter) expr(id string, x expr) string {
+	switch x := x.(type) {
+	case numberLit:
+		return strconv.FormatFloat(x.value, 'g', -1, 64)
+	case stringLit:
+		return quote(x.value)
+	case boolLit:
+		return strconv.FormatBool(x.value)
+	case ident:
+		return fmt.Sprintf("__get(%s, %s, %s)", x.name, quote(x.name), quote(id))
+	case unaryExpr:
+		return "(" + x.op + e.expr(id, x.x) + ")"
+	case binaryExpr:
+		a, b := e.expr(id, x.x), e.expr(id, x.y)
+		switch x.op {
+		case "/":
+			return fmt.Sprintf("__div(%s, %s, %s)", a, b, quote(id))
+		case "%":
+			return fmt.Sprintf("__mod(%s, %s, %s)", a, b, quote(id))
+		}
+		return "(" + a + " " + x.op + " " + b + ")"
+	case callExpr:
+		args := make([]string, len(x.args))
+		for i, arg := range x.args {
+			args[i] = e.expr(id, arg)
+		}
+		if x.fn == "hasInput" {
+			return "hasInput()"
+		}
+		return "Math." + x.fn + "(" + strings.Join(args, ", ") + ")"
+	}
+	panic(fmt.Sprintf("flowchart: unknown expression %T", x))
+}
+
+// quote는 s를 JavaScript 문자열 리터럴로 씁니다. JSON 문자열은 JavaScript에서도
+// 그대로 쓸 수 있습니다.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,228 @@
+package flowchart
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+)
+
+// sumGraph는 n을 입력받아 1부터 n까지의 합을 출력합니다 (while 반복)
+func sumGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "in", Type: Input, Var: "n"},
+			{ID: "init", Type: Process, Expr: "i = 1; sum = 0"},
+			{ID: "d", Type: Decision, Expr: "i <= n"},
+			{ID: "add", Type: Process, Expr: "sum += i\ni += 1"},
+			{ID: "out", Type: Output, Expr: "sum"},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "in"},
+			{From: "in", To: "init"},
+			{From: "init", To: "d"},
+			{From: "d", To: "add", Label: BranchYes},
+			{From: "add", To: "d"},
+			{From: "d", To: "out", Label: BranchNo},
+			{From: "out", To: "e"},
+		},
+	}
+}
+
+// signGraph는 입력한 수의 부호를 출력합니다 (중첩된 if/else)
+func signGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "in", Type: Input, Var: "x"},
+			{ID: "pos", Type: Decision, Expr: "x > 0"},
+			{ID: "neg", Type: Decision, Expr: "x < 0"},
+			{ID: "p", Type: Output, Expr: `"positive"`},
+			{ID: "m", Type: Output, Expr: `"negative"`},
+			{ID: "z", Type: Output, Expr: `"zero"`},
+			{ID: "done", Type: Output, Expr: `"done"`},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "in"},
+			{From: "in", To: "pos"},
+			{From: "pos", To: "p", Label: BranchYes},
+			{From: "pos", To: "neg", Label: BranchNo},
+			{From: "neg", To: "m", Label: BranchYes},
+			{From: "neg", To: "z", Label: BranchNo},
+			{From: "p", To: "done"},
+			{From: "m", To: "done"},
+			{From: "z", To: "done"},
+			{From: "done", To: "e"},
+		},
+	}
+}
+
+// countdownGraph는 반복 조건을 마지막에 확인하는 do-while 모양이라
+// 상태 기계로 컴파일됩니다
+func countdownGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "in", Type: Input, Var: "n"},
+			{ID: "out", Type: Output, Expr: "n"},
+			{ID: "dec", Type: Process, Expr: "n -= 1"},
+			{ID: "d", Type: Decision, Expr: "n > 0"},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "in"},
+			{From: "in", To: "out"},
+			{From: "out", To: "dec"},
+			{From: "dec", To: "d"},
+			{From: "d", To: "out", Label: BranchYes},
+			{From: "d", To: "e", Label: BranchNo},
+		},
+	}
+}
+
+// echoGraph는 입력이 남아 있는 동안 읽은 값을 한 줄에 이어 출력합니다
+func echoGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "d", Type: Decision, Expr: "hasInput()"},
+			{ID: "in", Type: Input, Var: "x"},
+			{ID: "out", Type: Output, Expr: `x + ","`, NoNewline: true},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "d"},
+			{From: "d", To: "in", Label: BranchYes},
+			{From: "d", To: "e", Label: BranchNo},
+			{From: "in", To: "out"},
+			{From: "out", To: "d"},
+		},
+	}
+}
+
+// divGraph는 두 수를 입력받아 나눈 몫을 출력합니다
+func divGraph() *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "a", Type: Input, Var: "a"},
+			{ID: "b", Type: Input, Var: "b"},
+			{ID: "out", Type: Output, Expr: "floor(a / b)"},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "a"},
+			{From: "a", To: "b"},
+			{From: "b", To: "out"},
+			{From: "out", To: "e"},
+		},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	j := judge.NewJudge(2, 10)
+	limits := judge.Limits{Timeout: time.Second, OutputLines: 100}
+
+	tests := []struct {
+		name     string
+		graph    *Graph
+		machine  bool // 상태 기계로 컴파일되어야 하는지
+		input    []string
+		output   []string
+		want     judge.Verdict
+		contains string // 실패 메시지에 들어 있어야 하는 문구
+	}{
+		{name: "while", graph: sumGraph(), input: []string{"10"}, output: []string{"55"}, want: judge.Accepted},
+		{name: "while zero times", graph: sumGraph(), input: []string{"0"}, output: []string{"0"}, want: judge.Accepted},
+		{name: "if else positive", graph: signGraph(), input: []string{"3"}, output: []string{"positive", "done"}, want: judge.Accepted},
+		{name: "if else zero", graph: signGraph(), input: []string{"0"}, output: []string{"zero", "done"}, want: judge.Accepted},
+		{name: "state machine", graph: countdownGraph(), machine: true, input: []string{"3"}, output: []string{"3", "2", "1"}, want: judge.Accepted},
+		{name: "division", graph: divGraph(), input: []string{"7", "2"}, output: []string{"3"}, want: judge.Accepted},
+		{name: "read until end of input", graph: echoGraph(), input: []string{"1", "a", "3"}, output: []string{"1,a,3,"}, want: judge.Accepted},
+		{name: "input exhausted", graph: divGraph(), input: []string{"7"}, output: []string{"0"}, want: judge.RuntimeError, contains: "EOF"},
+		{name: "division by zero", graph: divGraph(), input: []string{"7", "0"}, output: []string{"0"}, want: judge.RuntimeError, contains: "블록 out: 0으로 나눌 수 없습니다"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Compile(tt.graph)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if machine := strings.Contains(code, "__pc"); machine != tt.machine {
+				t.Errorf("state machine = %v, want %v\n%s", machine, tt.machine, code)
+			}
+
+			results, err := j.Run(context.Background(), judge.Script(code), []judge.TestCase{{ID: 1, Input: tt.input, Output: tt.output}}, limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := results[0]
+			if r.Verdict != tt.want {
+				t.Fatalf("verdict = %s (%s %v), want %s\n%s", r.Verdict, r.Message, r.Error, tt.want, code)
+			}
+			if tt.contains != "" && (r.Error == nil || !strings.Contains(r.Error.Error(), tt.contains)) {
+				t.Errorf("error = %v, want it to contain %q", r.Error, tt.contains)
+			}
+		})
+	}
+}
+
+func TestCompileRejectsInvalidGraph(t *testing.T) {
+	g := divGraph()
+	g.Nodes[3].Expr = "a / "
+	if _, err := Compile(g); err == nil {
+		t.Fatal("expected an error for a malformed expression")
+	}
+}
+
+func TestCompileRejectsOversizedGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(g *Graph)
+	}{
+		{"long expression", func(g *Graph) { g.Nodes[3].Expr = strings.Repeat("a+", MaxExprLength) + "a" }},
+		{"deep parentheses", func(g *Graph) { g.Nodes[3].Expr = strings.Repeat("(", 200) + "a" + strings.Repeat(")", 200) }},
+		{"deep unary", func(g *Graph) { g.Nodes[3].Expr = strings.Repeat("-", 200) + "a" }},
+		{"too many edges", func(g *Graph) {
+			for len(g.Edges) <= MaxEdges {
+				g.Edges = append(g.Edges, g.Edges[0])
+			}
+		}},
+		{"total expression length", func(g *Graph) {
+			for i := 0; i*MaxExprLength <= MaxTotalExprLength; i++ {
+				g.Nodes = append(g.Nodes, Node{ID: fmt.Sprint("x", i), Type: Output, Expr: strings.Repeat("1", MaxExprLength)})
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := divGraph()
+			tt.modify(g)
+			if _, err := Compile(g); err == nil || !strings.Contains(err.Error(), "너무") {
+				t.Fatalf("err = %v, want a size error", err)
+			}
+		})
+	}
+
+	// 제한 안에서 가장 긴 식도 금방 읽는다
+	g := divGraph()
+	g.Nodes[3].Expr = strings.Repeat("a+", MaxExprLength/2-1) + "a"
+	started := time.Now()
+	if _, err := Compile(g); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("compiling a %d-character expression took %v", len(g.Nodes[3].Expr), elapsed)
+	}
+}
@@ -0,0 +1,437 @@
+// flowchart/expr.go
+package flowchart
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 블록 안에 쓰는 식은 JavaScript 문법의 작은 부분집합입니다. 숫자, 문자열,
+// true/false, 변수, 산술/비교/논리 연산자, 괄호와 몇 가지 수학 함수만 쓸 수
+// 있어서, 서버가 만든 코드에 임의의 JavaScript가 섞여 들어가지 않습니다.
+
+type expr interface{}
+
+type numberLit struct{ value float64 }
+
+type stringLit struct{ value string }
+
+type boolLit struct{ value bool }
+
+type ident struct{ name string }
+
+type unaryExpr struct {
+	op string
+	x  expr
+}
+
+type binaryExpr struct {
+	op   string
+	x, y expr
+}
+
+type callExpr struct {
+	fn   string
+	args []expr
+}
+
+// assignment는 처리 블록의 대입문 하나입니다 (op는 "=", "+=" 등)
+type assignment struct {
+	name  string
+	op    string
+	value expr
+}
+
+// builtins는 식에서 부를 수 있는 함수와 인자 수입니다 (-1이면 1개 이상)
+var builtins = map[string]int{
+	"abs":      1,
+	"floor":    1,
+	"ceil":     1,
+	"round":    1,
+	"sqrt":     1,
+	"pow":      2,
+	"min":      -1,
+	"max":      -1,
+	"random":   0,
+	"hasInput": 0, // 읽지 않은 입력이 남았는지
+}
+
+// reserved는 변수 이름으로 쓸 수 없는 단어입니다. JavaScript 예약어와 생성된
+// 코드가 쓰는 전역 이름을 막습니다.
+var reserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "implements": true, "import": true, "in": true, "instanceof": true, "interface": true,
+	"let": true, "new": true, "null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true, "yield": true, "await": true,
+	"undefined": true, "NaN": true, "Infinity": true, "eval": true, "arguments": true,
+	"Math": true, "Number": true, "String": true, "Error": true, "prompt": true, "console": true,
+	"print": true, "readLine": true, "readInt": true, "readAll": true,
+}
+
+// checkVariable은 name을 변수 이름으로 쓸 수 있는지 확인합니다
+func checkVariable(name string) error {
+	if !IsIdentifier(name) {
+		return fmt.Errorf("변수 이름 %q가 올바르지 않습니다", name)
+	}
+	if reserved[name] || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("%q는 변수 이름으로 쓸 수 없습니다", name)
+	}
+	if _, ok := builtins[name]; ok {
+		return fmt.Errorf("%q는 함수 이름이라 변수로 쓸 수 없습니다", name)
+	}
+	return nil
+}
+
+// token
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokSeparator // ';' 또는 줄바꿈
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+// operators는 길이가 긴 것부터 맞춰 봅니다
+var operators = []string{
+	"===", "!==",
+	"==", "!=", "<=", ">=", "&&", "||", "+=", "-=", "*=", "/=", "%=",
+	"+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ",", "=",
+}
+
+// 블록 하나의 식 길이(글자 수)와 식을 읽을 때 들어갈 수 있는 괄호·단항 연산자의
+// 깊이
+const (
+	MaxExprLength = 1000
+	maxExprDepth  = 100
+)
+
+// hasOperator는 runes가 op로 시작하는지 확인합니다. 연산자는 모두 ASCII입니다.
+func hasOperator(runes []rune, op string) bool {
+	if len(runes) < len(op) {
+		return false
+	}
+	for k := 0; k < len(op); k++ {
+		if runes[k] != rune(op[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(src string) ([]token, error) {
+	runes := []rune(src)
+	if len(runes) > MaxExprLength {
+		return nil, fmt.Errorf("식이 너무 깁니다 (%d자, 최대 %d자)", len(runes), MaxExprLength)
+	}
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n' || r == ';':
+			tokens = append(tokens, token{kind: tokSeparator, text: string(r)})
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			text := string(runes[i:j])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("잘못된 숫자 %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num})
+			i = j
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+					switch runes[j] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						sb.WriteRune(runes[j])
+					}
+					continue
+				}
+				if runes[j] == '\n' {
+					break
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) || runes[j] != r {
+				return nil, fmt.Errorf("문자열이 닫히지 않았습니다")
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String()})
+			i = j + 1
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:j])})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if hasOperator(runes[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("알 수 없는 기호 %q", string(r))
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// parser
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int // parseUnary가 몇 겹으로 들어왔는지
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		return fmt.Errorf("%q가 필요합니다", op)
+	}
+	p.next()
+	return nil
+}
+
+// 연산자 우선순위 (낮은 것부터)
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "===", "!=="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseBinary(0)
+}
+
+func (p *parser) parseBinary(level int) (expr, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+	x, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(precedence[level]...) {
+		op := p.next().text
+		// 초보자가 헷갈리지 않도록 ==와 ===는 같은 뜻으로 다룬다
+		switch op {
+		case "===":
+			op = "=="
+		case "!==":
+			op = "!="
+		}
+		y, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = binaryExpr{op: op, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	// 괄호, 함수 인자, 단항 연산자는 모두 여기를 다시 지나므로 깊이를 여기서 센다
+	if p.depth++; p.depth > maxExprDepth {
+		return nil, fmt.Errorf("식이 너무 깊게 중첩되어 있습니다 (최대 %d단계)", maxExprDepth)
+	}
+	defer func() { p.depth-- }()
+	if p.isOp("!", "-", "+") {
+		op := p.next().text
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: op, x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return numberLit{t.num}, nil
+	case tokString:
+		return stringLit{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return boolLit{true}, nil
+		case "false":
+			return boolLit{false}, nil
+		}
+		if arity, ok := builtins[t.text]; ok {
+			return p.parseCall(t.text, arity)
+		}
+		if err := checkVariable(t.text); err != nil {
+			return nil, err
+		}
+		return ident{t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+		return nil, fmt.Errorf("예상하지 못한 %q", t.text)
+	case tokSeparator:
+		return nil, fmt.Errorf("식이 끝나지 않았습니다")
+	default:
+		return nil, fmt.Errorf("식이 비어 있거나 끝나지 않았습니다")
+	}
+}
+
+func (p *parser) parseCall(fn string, arity int) (expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, fmt.Errorf("%s 함수 뒤에 %v", fn, err)
+	}
+	var args []expr
+	if !p.isOp(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if (arity >= 0 && len(args) != arity) || (arity < 0 && len(args) == 0) {
+		return nil, fmt.Errorf("%s 함수의 인자 수가 맞지 않습니다", fn)
+	}
+	return callExpr{fn: fn, args: args}, nil
+}
+
+// parseExpression은 출력/판단 블록의 식 하나를 읽습니다
+func parseExpression(src string) (expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("식 뒤에 필요 없는 %q가 있습니다", t.text)
+	}
+	return x, nil
+}
+
+// parseAssignments는 처리 블록의 대입문들을 읽습니다. 여러 대입문은 ';'나
+// 줄바꿈으로 나눕니다.
+func parseAssignments(src string) ([]assignment, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	var stmts []assignment
+	for {
+		for p.peek().kind == tokSeparator {
+			p.next()
+		}
+		if p.peek().kind == tokEOF {
+			break
+		}
+
+		t := p.next()
+		if t.kind != tokIdent {
+			return nil, fmt.Errorf("대입문은 변수 이름으로 시작해야 합니다")
+		}
+		if err := checkVariable(t.text); err != nil {
+			return nil, err
+		}
+		if !p.isOp("=", "+=", "-=", "*=", "/=", "%=") {
+			return nil, fmt.Errorf("%s 뒤에 '='가 필요합니다", t.text)
+		}
+		op := p.next().text
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, assignment{name: t.text, op: op, value: value})
+
+		if k := p.peek().kind; k != tokSeparator && k != tokEOF {
+			return nil, fmt.Errorf("대입문 뒤에 필요 없는 %q가 있습니다", p.peek().text)
+		}
+	}
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("대입문이 없습니다")
+	}
+	return stmts, nil
+}
@@ -0,0 +1,101 @@
+// flowchart/graph.go
+package flowchart
+
+// Version은 현재 순서도 JSON 형식의 버전입니다. 형식이 바뀌면 올립니다.
+const Version = 1
+
+// NodeType은 순서도 블록의 종류입니다
+type NodeType string
+
+const (
+	Start    NodeType = "start"    // 시작
+	End      NodeType = "end"      // 끝
+	Process  NodeType = "process"  // 처리 (대입문)
+	Input    NodeType = "input"    // 입력 (prompt)
+	Output   NodeType = "output"   // 출력 (console.log, 줄바꿈 없이는 print)
+	Decision NodeType = "decision" // 판단 (조건에 따라 두 갈래)
+)
+
+// decision 노드에서 나가는 간선의 이름
+const (
+	BranchYes = "yes"
+	BranchNo  = "no"
+)
+
+// Graph는 학생이 만든 순서도입니다. 블록 코딩 화면과 같은 모양을 다시 그릴 수 있도록
+// 블록 위치까지 저장합니다.
+type Graph struct {
+	Version int    `json:"version"`
+	Nodes   []Node `json:"nodes"`
+	Edges   []Edge `json:"edges"`
+}
+
+type Node struct {
+	ID   string   `json:"id"`
+	Type NodeType `json:"type"`
+	// process는 대입문 (예: "sum = sum + i"), output은 출력할 식,
+	// decision은 조건식입니다
+	Expr string `json:"expr,omitempty"`
+	// input 노드가 입력받을 변수 이름
+	Var string `json:"var,omitempty"`
+	// output 노드가 줄을 바꾸지 않고 출력할지
+	NoNewline bool      `json:"noNewline,omitempty"`
+	Position  *Position `json:"position,omitempty"`
+}
+
+// Position은 화면에서 블록의 위치입니다
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// decision 노드에서 나가는 간선은 BranchYes 또는 BranchNo
+	Label string `json:"label,omitempty"`
+}
+
+// index는 노드 ID로 노드와 나가는 간선을 찾기 위한 색인입니다
+type index struct {
+	nodes    map[string]*Node
+	outgoing map[string][]Edge
+	incoming map[string][]Edge
+}
+
+func newIndex(g *Graph) *index {
+	idx := &index{
+		nodes:    make(map[string]*Node, len(g.Nodes)),
+		outgoing: make(map[string][]Edge, len(g.Nodes)),
+		incoming: make(map[string][]Edge, len(g.Nodes)),
+	}
+	for i := range g.Nodes {
+		idx.nodes[g.Nodes[i].ID] = &g.Nodes[i]
+	}
+	for _, e := range g.Edges {
+		idx.outgoing[e.From] = append(idx.outgoing[e.From], e)
+		idx.incoming[e.To] = append(idx.incoming[e.To], e)
+	}
+	return idx
+}
+
+// next는 decision이 아닌 노드의 다음 노드 ID입니다
+func (idx *index) next(id string) string {
+	if out := idx.outgoing[id]; len(out) > 0 {
+		return out[0].To
+	}
+	return ""
+}
+
+// branches는 decision 노드의 yes/no 쪽 다음 노드 ID입니다
+func (idx *index) branches(id string) (yes, no string) {
+	for _, e := range idx.outgoing[id] {
+		switch e.Label {
+		case BranchYes:
+			yes = e.To
+		case BranchNo:
+			no = e.To
+		}
+	}
+	return yes, no
+}
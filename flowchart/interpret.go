@@ -0,0 +1,280 @@
+// flowchart/interpret.go
+package flowchart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+)
+
+// nodeError는 실행 중 블록에서 난 오류입니다. 채점 결과에 블록 ID가 함께 담겨
+// 화면에서 어느 블록이 잘못됐는지 보여줄 수 있습니다.
+type nodeError struct {
+	node string
+	err  error
+}
+
+func (e *nodeError) Error() string  { return fmt.Sprintf("블록 %s: %v", e.node, e.err) }
+func (e *nodeError) Unwrap() error  { return e.err }
+func (e *nodeError) NodeID() string { return e.node }
+
+var errDivisionByZero = errors.New("0으로 나눌 수 없습니다")
+
+// interpreter는 순서도를 JavaScript로 바꾸지 않고 블록 단위로 직접 실행합니다
+type interpreter struct {
+	graph *Graph
+	prog  *program
+}
+
+// NewInterpreter는 순서도를 블록 단위로 실행하는 Executor를 만듭니다. 실행
+// 시간 대신 실행한 블록 수(judge.Limits.Steps)로 끝나지 않는 반복을 막고,
+// 실행 오류는 "블록 X: 0으로 나눌 수 없습니다"처럼 블록 ID와 함께 알려줍니다.
+func NewInterpreter(g *Graph) judge.Executor {
+	return &interpreter{graph: g}
+}
+
+func (in *interpreter) Compile() error {
+	p, err := load(in.graph)
+	if err != nil {
+		return err
+	}
+	in.prog = p
+	return nil
+}
+
+func (in *interpreter) Prepare(env *judge.Env) judge.Execution {
+	return &execution{prog: in.prog, env: env, vars: make(map[string]value)}
+}
+
+// execution은 테스트케이스 하나의 실행 상태입니다
+type execution struct {
+	prog        *program
+	env         *judge.Env
+	vars        map[string]value
+	interrupted atomic.Pointer[error]
+}
+
+func (x *execution) Interrupt(reason error) {
+	x.interrupted.CompareAndSwap(nil, &reason)
+}
+
+func (x *execution) Run() error {
+	p := x.prog
+	for id := p.start; ; {
+		if reason := x.interrupted.Load(); reason != nil {
+			return *reason
+		}
+		if err := x.env.Step(); err != nil {
+			return err
+		}
+
+		next, err := x.step(p.node(id))
+		if x.env.Tracing() {
+			x.env.RecordStep(id, x.snapshot())
+		}
+		if err != nil {
+			return &nodeError{node: id, err: err}
+		}
+		if next == "" {
+			return nil
+		}
+		id = next
+	}
+}
+
+// step은 블록 하나를 실행하고 다음 블록 ID를 돌려줍니다. 끝 블록이면 ""입니다.
+func (x *execution) step(n *Node) (string, error) {
+	p := x.prog
+	switch n.Type {
+	case End:
+		return "", nil
+	case Input:
+		line, err := x.env.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		x.vars[n.Var] = inputValue(line)
+	case Output:
+		v, err := x.eval(p.exprs[n.ID])
+		if err != nil {
+			return "", err
+		}
+		if n.NoNewline {
+			x.env.Write(toString(v))
+		} else {
+			x.env.WriteLine(toString(v))
+		}
+	case Process:
+		for _, a := range p.assigns[n.ID] {
+			var v value
+			var err error
+			if a.op == "=" {
+				v, err = x.eval(a.value)
+			} else {
+				v, err = x.eval(binaryExpr{op: a.op[:len(a.op)-1], x: ident{a.name}, y: a.value})
+			}
+			if err != nil {
+				return "", err
+			}
+			x.vars[a.name] = v
+		}
+	case Decision:
+		v, err := x.eval(p.exprs[n.ID])
+		if err != nil {
+			return "", err
+		}
+		yes, no := p.idx.branches(n.ID)
+		if toBool(v) {
+			return yes, nil
+		}
+		return no, nil
+	}
+	return p.idx.next(n.ID), nil
+}
+
+// snapshot은 실행 기록에 남길 변수 값입니다. 문자열은 숫자와 구분되도록 따옴표로 감쌉니다.
+func (x *execution) snapshot() map[string]string {
+	vars := make(map[string]string, len(x.vars))
+	for name, v := range x.vars {
+		if s, ok := v.(string); ok {
+			vars[name] = quote(s)
+		} else {
+			vars[name] = toString(v)
+		}
+	}
+	return vars
+}
+
+// eval은 식의 값을 JavaScript와 같은 규칙으로 계산합니다
+func (x *execution) eval(e expr) (value, error) {
+	switch e := e.(type) {
+	case numberLit:
+		return e.value, nil
+	case stringLit:
+		return e.value, nil
+	case boolLit:
+		return e.value, nil
+	case ident:
+		v, ok := x.vars[e.name]
+		if !ok {
+			return nil, fmt.Errorf("변수 %s에 값이 없습니다", e.name)
+		}
+		return v, nil
+	case unaryExpr:
+		v, err := x.eval(e.x)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "!":
+			return !toBool(v), nil
+		case "-":
+			return -toNumber(v), nil
+		default:
+			return toNumber(v), nil
+		}
+	case binaryExpr:
+		return x.binary(e)
+	case callExpr:
+		switch e.fn {
+		case "random":
+			return x.env.Random(), nil // 컴파일한 코드의 Math.random과 같은 수열
+		case "hasInput":
+			return x.env.HasInput(), nil
+		}
+		args := make([]float64, len(e.args))
+		for i, arg := range e.args {
+			v, err := x.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = toNumber(v)
+		}
+		return call(e.fn, args), nil
+	}
+	return nil, fmt.Errorf("알 수 없는 식 %T", e)
+}
+
+func (x *execution) binary(e binaryExpr) (value, error) {
+	a, err := x.eval(e.x)
+	if err != nil {
+		return nil, err
+	}
+	// &&와 ||는 JavaScript처럼 오른쪽을 필요할 때만 계산하고 피연산자 값을 돌려준다
+	switch e.op {
+	case "&&":
+		if !toBool(a) {
+			return a, nil
+		}
+		return x.eval(e.y)
+	case "||":
+		if toBool(a) {
+			return a, nil
+		}
+		return x.eval(e.y)
+	}
+
+	b, err := x.eval(e.y)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "+":
+		_, as := a.(string)
+		_, bs := b.(string)
+		if as || bs {
+			return toString(a) + toString(b), nil
+		}
+		return toNumber(a) + toNumber(b), nil
+	case "-":
+		return toNumber(a) - toNumber(b), nil
+	case "*":
+		return toNumber(a) * toNumber(b), nil
+	case "/", "%":
+		if b == value(float64(0)) {
+			return nil, errDivisionByZero
+		}
+		if e.op == "/" {
+			return toNumber(a) / toNumber(b), nil
+		}
+		return math.Mod(toNumber(a), toNumber(b)), nil
+	case "==":
+		return looseEqual(a, b), nil
+	case "!=":
+		return !looseEqual(a, b), nil
+	default:
+		return compare(e.op, a, b), nil
+	}
+}
+
+// call은 식에서 쓸 수 있는 수학 함수를 JavaScript의 Math 함수처럼 계산합니다
+func call(fn string, args []float64) float64 {
+	switch fn {
+	case "abs":
+		return math.Abs(args[0])
+	case "floor":
+		return math.Floor(args[0])
+	case "ceil":
+		return math.Ceil(args[0])
+	case "round":
+		return jsRound(args[0])
+	case "sqrt":
+		return math.Sqrt(args[0])
+	case "pow":
+		return math.Pow(args[0], args[1])
+	case "min", "max":
+		result := args[0]
+		for _, a := range args[1:] {
+			if math.IsNaN(a) || math.IsNaN(result) {
+				result = math.NaN()
+			} else if (fn == "min") == (a < result) {
+				result = a
+			}
+		}
+		return result
+	}
+	return math.NaN()
+}
@@ -0,0 +1,195 @@
+package flowchart
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+)
+
+// exprGraph는 식 하나를 출력하는 순서도입니다
+func exprGraph(src string) *Graph {
+	return &Graph{
+		Version: Version,
+		Nodes: []Node{
+			{ID: "s", Type: Start},
+			{ID: "in", Type: Input, Var: "x"},
+			{ID: "out", Type: Output, Expr: src},
+			{ID: "e", Type: End},
+		},
+		Edges: []Edge{
+			{From: "s", To: "in"},
+			{From: "in", To: "out"},
+			{From: "out", To: "e"},
+		},
+	}
+}
+
+// 인터프리터는 컴파일한 코드와 같은 값을 출력해야 한다
+func TestInterpreterMatchesCompiledCode(t *testing.T) {
+	j := judge.NewJudge(2, 10)
+	limits := judge.Limits{Timeout: time.Second, OutputLines: 100, Steps: 10000}
+
+	tests := []struct {
+		graph  *Graph
+		input  string
+		output []string
+	}{
+		{sumGraph(), "100", []string{"5050"}},
+		{signGraph(), "-4", []string{"negative", "done"}},
+		{countdownGraph(), "5", []string{"5", "4", "3", "2", "1"}},
+		{echoGraph(), "7", []string{"7,"}},
+		{exprGraph("x + 1"), "41", []string{"42"}},
+		{exprGraph("x + 1"), "abc", []string{"abc1"}},
+		{exprGraph(`x + "!"`), "3", []string{"3!"}},
+		{exprGraph("x / 3"), "10", []string{"3.3333333333333335"}},
+		{exprGraph("x % 3"), "-7", []string{"-1"}},
+		{exprGraph("x * 1000000000000000000000"), "1.5", []string{"1.5e+21"}},
+		{exprGraph("x / 1000000"), "1", []string{"0.000001"}},
+		{exprGraph("x / 10000000"), "1", []string{"1e-7"}},
+		{exprGraph("x == 3 && x"), "3", []string{"3"}},
+		{exprGraph(`x == 3 || "no"`), "4", []string{"no"}},
+		{exprGraph("!x"), "0", []string{"true"}},
+		{exprGraph(`x < "b"`), "a", []string{"true"}},
+		{exprGraph("x - 1"), "abc", []string{"NaN"}},
+		{exprGraph("round(x) + floor(x) + ceil(x)"), "-2.5", []string{"-7"}},
+		{exprGraph("max(x, 2, 3) + min(x, 2) + pow(x, 2) + sqrt(abs(x))"), "-4", []string{"17"}},
+		{exprGraph(`x == "07"`), "7", []string{"true"}},
+		{exprGraph("x"), "  12  ", []string{"12"}},
+		{exprGraph("x + 0.1"), "0.2", []string{"0.30000000000000004"}},
+	}
+	for _, tt := range tests {
+		code, err := Compile(tt.graph)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases := []judge.TestCase{{ID: 1, Input: []string{tt.input}, Output: tt.output}}
+
+		executors := map[string]judge.Executor{
+			"compiled":    judge.Script(code),
+			"interpreted": NewInterpreter(tt.graph),
+		}
+		for name, exec := range executors {
+			results, err := j.Run(context.Background(), exec, cases, limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := results[0]; r.Verdict != judge.Accepted {
+				t.Errorf("%s %q with input %q: %s %s %v", name, tt.graph.Nodes[len(tt.graph.Nodes)-2].Expr, tt.input,
+					r.Verdict, r.Message, r.Error)
+			}
+		}
+	}
+}
+
+// random()은 두 실행 방법에서 같은 시드로 같은 값을 낸다
+func TestInterpreterRandomMatchesCompiledCode(t *testing.T) {
+	j := judge.NewJudge(2, 10)
+	limits := judge.Limits{Timeout: time.Second, OutputLines: 100, Steps: 10000}
+	graph := exprGraph("floor(random() * 1000000) + x")
+	code, err := Compile(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []judge.TestCase{{ID: 1, Input: []string{"0"}, Seed: 42}}
+
+	var outputs []string
+	for _, exec := range []judge.Executor{judge.Script(code), NewInterpreter(graph)} {
+		results, err := j.Run(context.Background(), exec, cases, limits, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results[0].Output) != 1 {
+			t.Fatalf("output = %q (%s %v)", results[0].Output, results[0].Verdict, results[0].Error)
+		}
+		outputs = append(outputs, results[0].Output[0])
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("compiled %q, interpreted %q", outputs[0], outputs[1])
+	}
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	j := judge.NewJudge(2, 10)
+	limits := judge.Limits{Timeout: time.Second, OutputLines: 100, Steps: 1000}
+
+	infinite := sumGraph()
+	infinite.Nodes[4].Expr = "sum += i" // i가 늘어나지 않는다
+
+	tests := []struct {
+		name    string
+		graph   *Graph
+		input   []string
+		verdict judge.Verdict
+		node    string
+		message string
+	}{
+		{"division by zero", divGraph(), []string{"7", "0"}, judge.RuntimeError, "out", "블록 out: 0으로 나눌 수 없습니다"},
+		{"input exhausted", divGraph(), []string{"7"}, judge.RuntimeError, "b", "블록 b: EOF: 입력을 모두 읽었습니다"},
+		{"unset variable", exprGraph("y"), []string{"1"}, judge.RuntimeError, "out", "블록 out: 변수 y에 값이 없습니다"},
+		{"step limit", infinite, []string{"1"}, judge.TimeLimitExceeded, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := j.Run(context.Background(), NewInterpreter(tt.graph), []judge.TestCase{{ID: 1, Input: tt.input}}, limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := results[0]
+			if r.Verdict != tt.verdict || r.Node != tt.node {
+				t.Fatalf("verdict = %s at node %q (%s %v), want %s at node %q", r.Verdict, r.Node, r.Message, r.Error, tt.verdict, tt.node)
+			}
+			if tt.message != "" && (r.Error == nil || r.Error.Error() != tt.message) {
+				t.Errorf("error = %v, want %q", r.Error, tt.message)
+			}
+		})
+	}
+}
+
+func TestInterpreterCompileError(t *testing.T) {
+	g := divGraph()
+	g.Edges = g.Edges[:3]
+	results, err := judge.NewJudge(1, 1).Run(context.Background(), NewInterpreter(g), []judge.TestCase{{ID: 1}}, judge.Limits{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Verdict != judge.CompileError {
+		t.Fatalf("verdict = %s, want %s", results[0].Verdict, judge.CompileError)
+	}
+}
+
+func TestInterpreterTrace(t *testing.T) {
+	j := judge.NewJudge(1, 1)
+	limits := judge.Limits{Timeout: time.Second, Steps: 1000}
+	tc := judge.TestCase{ID: 1, Input: []string{"7", "2"}, Output: []string{"3"}}
+
+	trace, err := j.Trace(context.Background(), NewInterpreter(divGraph()), tc, limits, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Result.Verdict != judge.Accepted || trace.Truncated {
+		t.Fatalf("verdict = %s, truncated = %v", trace.Result.Verdict, trace.Truncated)
+	}
+
+	var got []string
+	for _, ev := range trace.Events {
+		got = append(got, ev.Kind+":"+ev.Node+ev.Value)
+	}
+	want := []string{"step:s", "input:7", "step:a", "input:2", "step:b", "output:3", "step:out", "step:e"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if vars := trace.Events[4].Vars; vars["a"] != "7" || vars["b"] != "2" {
+		t.Errorf("vars after b = %v", vars)
+	}
+
+	trace, err = j.Trace(context.Background(), NewInterpreter(sumGraph()), judge.TestCase{ID: 1, Input: []string{"100"}}, limits, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Events) != 10 || !trace.Truncated {
+		t.Errorf("got %d events (truncated = %v), want 10 truncated", len(trace.Events), trace.Truncated)
+	}
+}
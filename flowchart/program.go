@@ -0,0 +1,63 @@
+// flowchart/program.go
+package flowchart
+
+// program은 검증을 마치고 블록 안의 식을 읽어 둔 순서도입니다.
+// 컴파일러와 인터프리터가 함께 씁니다.
+type program struct {
+	graph   *Graph
+	idx     *index
+	start   string
+	exprs   map[string]expr         // output, decision 블록의 식
+	assigns map[string][]assignment // process 블록의 대입문
+	vars    []string                // 값이 대입되는 변수 (처음 나온 순서)
+}
+
+func load(g *Graph) (*program, error) {
+	if err := Validate(g); err != nil {
+		return nil, err
+	}
+
+	p := &program{
+		graph:   g,
+		idx:     newIndex(g),
+		exprs:   make(map[string]expr),
+		assigns: make(map[string][]assignment),
+	}
+	seen := make(map[string]bool)
+	addVar := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			p.vars = append(p.vars, name)
+		}
+	}
+
+	// Validate가 식을 이미 확인했으므로 여기서는 에러가 나지 않는다
+	for _, n := range g.Nodes {
+		switch n.Type {
+		case Start:
+			p.start = n.ID
+		case Input:
+			addVar(n.Var)
+		case Process:
+			stmts, err := parseAssignments(n.Expr)
+			if err != nil {
+				return nil, err
+			}
+			p.assigns[n.ID] = stmts
+			for _, s := range stmts {
+				addVar(s.name)
+			}
+		case Output, Decision:
+			x, err := parseExpression(n.Expr)
+			if err != nil {
+				return nil, err
+			}
+			p.exprs[n.ID] = x
+		}
+	}
+	return p, nil
+}
+
+func (p *program) node(id string) *Node {
+	return p.idx.nodes[id]
+}
@@ -0,0 +1,185 @@
+// flowchart/structure.go
+package flowchart
+
+import "errors"
+
+// errUnstructured는 순서도를 if/while만으로 나타낼 수 없을 때 반환됩니다.
+// 이런 순서도는 블록 사이를 직접 옮겨 다니는 방식으로 실행합니다.
+var errUnstructured = errors.New("구조화할 수 없는 순서도")
+
+// stmt는 순서도를 구조화한 결과입니다
+type stmt interface{}
+
+// nodeStmt는 process, input, output 블록 하나입니다
+type nodeStmt struct{ id string }
+
+// endStmt는 끝 블록에 도착해 실행을 마치는 곳입니다
+type endStmt struct{ id string }
+
+// ifStmt는 반복이 아닌 판단 블록입니다
+type ifStmt struct {
+	id        string
+	then, els []stmt
+	hasElse   bool
+}
+
+// loopStmt는 한쪽 갈래가 자기 자신으로 돌아오는 판단 블록입니다.
+// negate가 true면 no 쪽이 반복할 부분입니다.
+type loopStmt struct {
+	id     string
+	negate bool
+	body   []stmt
+}
+
+type structurer struct {
+	prog    *program
+	emitted map[string]bool
+}
+
+// structure는 순서도 전체를 if/while 문의 나열로 바꿉니다.
+// 그럴 수 없으면 errUnstructured를 반환합니다.
+func structure(p *program) ([]stmt, error) {
+	s := &structurer{prog: p, emitted: make(map[string]bool)}
+	return s.sequence(p.start, nil)
+}
+
+// sequence는 from부터 stops의 마지막(가장 안쪽) 블록에 닿을 때까지를 구조화합니다.
+// 바깥쪽 stop에 먼저 닿는 경우는 break/continue가 필요하므로 구조화하지 않습니다.
+func (s *structurer) sequence(from string, stops []string) ([]stmt, error) {
+	var out []stmt
+	for id := from; ; {
+		for i, stop := range stops {
+			if id == stop {
+				if i == len(stops)-1 {
+					return out, nil
+				}
+				return nil, errUnstructured
+			}
+		}
+
+		n := s.prog.node(id)
+		if n.Type == End {
+			return append(out, endStmt{id}), nil
+		}
+		if s.emitted[id] {
+			return nil, errUnstructured
+		}
+		s.emitted[id] = true
+
+		switch n.Type {
+		case Start:
+			id = s.prog.idx.next(id)
+		case Process, Input, Output:
+			out = append(out, nodeStmt{id})
+			id = s.prog.idx.next(id)
+		case Decision:
+			st, after, err := s.decision(id, stops)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, st)
+			if after == "" {
+				return out, nil
+			}
+			id = after
+		}
+	}
+}
+
+// decision은 판단 블록을 반복문이나 조건문으로 만들고, 그 다음에 이어서
+// 구조화할 블록을 돌려줍니다. 다음 블록이 없으면 ""입니다.
+func (s *structurer) decision(id string, stops []string) (stmt, string, error) {
+	yes, no := s.prog.idx.branches(id)
+	yesLoops := s.reaches(yes, id, stops)
+	noLoops := s.reaches(no, id, stops)
+
+	switch {
+	case yesLoops && noLoops:
+		return nil, "", errUnstructured
+	case yesLoops || noLoops:
+		body, exit, negate := yes, no, false
+		if noLoops {
+			body, exit, negate = no, yes, true
+		}
+		stmts, err := s.sequence(body, append(stops[:len(stops):len(stops)], id))
+		if err != nil {
+			return nil, "", err
+		}
+		return loopStmt{id: id, negate: negate, body: stmts}, exit, nil
+	}
+
+	// 두 갈래가 다시 만나는 곳까지를 각각 구조화한다
+	join := s.join(yes, no, stops)
+	branchStops := stops
+	if join != "" {
+		branchStops = append(stops[:len(stops):len(stops)], join)
+	}
+	then, err := s.sequence(yes, branchStops)
+	if err != nil {
+		return nil, "", err
+	}
+	els, err := s.sequence(no, branchStops)
+	if err != nil {
+		return nil, "", err
+	}
+	return ifStmt{id: id, then: then, els: els, hasElse: len(els) > 0}, join, nil
+}
+
+// reaches는 stops를 지나지 않고 from에서 target까지 갈 수 있는지 확인합니다
+func (s *structurer) reaches(from, target string, stops []string) bool {
+	return s.reachable(from, stops)[target]
+}
+
+// reachable은 stops를 지나지 않고 from에서 갈 수 있는 블록들입니다
+func (s *structurer) reachable(from string, stops []string) map[string]bool {
+	blocked := make(map[string]bool, len(stops))
+	for _, stop := range stops {
+		blocked[stop] = true
+	}
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if blocked[id] {
+			continue
+		}
+		for _, e := range s.prog.idx.outgoing[id] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return seen
+}
+
+// join은 a에서 가까운 순서대로 보았을 때 b에서도 닿는 첫 블록입니다.
+// 두 갈래가 다시 만나지 않으면 ""입니다.
+func (s *structurer) join(a, b string, stops []string) string {
+	fromB := s.reachable(b, stops)
+	blocked := make(map[string]bool, len(stops))
+	for _, stop := range stops {
+		blocked[stop] = true
+	}
+
+	seen := map[string]bool{a: true}
+	queue := []string{a}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if blocked[id] {
+			continue
+		}
+		if fromB[id] {
+			return id
+		}
+		for _, e := range s.prog.idx.outgoing[id] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return ""
+}
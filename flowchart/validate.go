@@ -0,0 +1,187 @@
+// flowchart/validate.go
+package flowchart
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 순서도 하나에 허용하는 블록 수, 간선 수, 모든 블록의 식을 합친 길이(글자 수)
+const (
+	MaxNodes           = 500
+	MaxEdges           = 2 * MaxNodes
+	MaxTotalExprLength = 50000
+)
+
+// ValidationError는 순서도에서 발견한 문제를 모두 담습니다
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "잘못된 순서도: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate는 순서도가 실행할 수 있는 모양인지 확인합니다. 시작 블록은 정확히
+// 하나여야 하고, 모든 간선은 있는 블록을 잇고, 모든 블록은 시작에서 닿을 수
+// 있어야 하며, 판단 블록은 yes/no 두 갈래를 가져야 합니다. 블록 안의 식도
+// 문법에 맞는지 확인합니다.
+func Validate(g *Graph) error {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if g.Version != Version {
+		report("지원하지 않는 순서도 버전 %d (현재 %d)", g.Version, Version)
+		return &ValidationError{problems}
+	}
+	if len(g.Nodes) > MaxNodes {
+		report("블록이 너무 많습니다 (%d개, 최대 %d개)", len(g.Nodes), MaxNodes)
+	}
+	if len(g.Edges) > MaxEdges {
+		report("간선이 너무 많습니다 (%d개, 최대 %d개)", len(g.Edges), MaxEdges)
+	}
+	total := 0
+	for _, n := range g.Nodes {
+		total += utf8.RuneCountInString(n.Expr)
+	}
+	if total > MaxTotalExprLength {
+		report("블록의 식을 모두 합친 길이가 너무 깁니다 (%d자, 최대 %d자)", total, MaxTotalExprLength)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+
+	// 블록
+	seen := make(map[string]bool, len(g.Nodes))
+	var start string
+	starts, ends := 0, 0
+	for _, n := range g.Nodes {
+		if n.ID == "" {
+			report("ID가 없는 블록이 있습니다")
+			continue
+		}
+		if seen[n.ID] {
+			report("블록 %s: ID가 중복됩니다", n.ID)
+			continue
+		}
+		seen[n.ID] = true
+
+		switch n.Type {
+		case Start:
+			starts++
+			start = n.ID
+		case End:
+			ends++
+		case Input:
+			if err := checkVariable(n.Var); err != nil {
+				report("블록 %s: %v", n.ID, err)
+			}
+		case Process:
+			if _, err := parseAssignments(n.Expr); err != nil {
+				report("블록 %s: %v", n.ID, err)
+			}
+		case Output, Decision:
+			if _, err := parseExpression(n.Expr); err != nil {
+				report("블록 %s: %v", n.ID, err)
+			}
+		default:
+			report("블록 %s: 알 수 없는 종류 %q", n.ID, n.Type)
+		}
+	}
+	if starts != 1 {
+		report("시작 블록은 정확히 하나여야 합니다 (%d개)", starts)
+	}
+	if ends == 0 {
+		report("끝 블록이 없습니다")
+	}
+
+	// 간선
+	for _, e := range g.Edges {
+		if !seen[e.From] || !seen[e.To] {
+			report("간선 %s → %s: 없는 블록을 가리킵니다", e.From, e.To)
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+
+	idx := newIndex(g)
+	for _, n := range g.Nodes {
+		out := idx.outgoing[n.ID]
+		switch n.Type {
+		case Start:
+			if len(idx.incoming[n.ID]) > 0 {
+				report("블록 %s: 시작 블록으로 들어오는 간선이 있습니다", n.ID)
+			}
+			if len(out) != 1 {
+				report("블록 %s: 나가는 간선이 하나여야 합니다 (%d개)", n.ID, len(out))
+			}
+		case End:
+			if len(out) > 0 {
+				report("블록 %s: 끝 블록에서 나가는 간선이 있습니다", n.ID)
+			}
+		case Decision:
+			yes, no := 0, 0
+			for _, e := range out {
+				switch e.Label {
+				case BranchYes:
+					yes++
+				case BranchNo:
+					no++
+				}
+			}
+			if len(out) != 2 || yes != 1 || no != 1 {
+				report("블록 %s: 판단 블록은 yes와 no 간선을 하나씩 가져야 합니다", n.ID)
+			}
+		default:
+			if len(out) != 1 {
+				report("블록 %s: 나가는 간선이 하나여야 합니다 (%d개)", n.ID, len(out))
+			}
+		}
+	}
+
+	// 시작에서 닿지 않는 블록
+	if start != "" {
+		reached := map[string]bool{start: true}
+		queue := []string{start}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, e := range idx.outgoing[id] {
+				if !reached[e.To] {
+					reached[e.To] = true
+					queue = append(queue, e.To)
+				}
+			}
+		}
+		for _, n := range g.Nodes {
+			if !reached[n.ID] {
+				report("블록 %s: 시작 블록에서 닿을 수 없습니다", n.ID)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+	return nil
+}
+
+// IsIdentifier는 name이 변수 이름으로 쓸 수 있는지 확인합니다.
+// 한글 변수 이름도 허용합니다.
+func IsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
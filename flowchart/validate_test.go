@@ -0,0 +1,75 @@
+package flowchart
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *Graph)
+		want   string // 에러 메시지에 들어 있어야 할 말, 비어 있으면 올바른 순서도
+	}{
+		{"valid", func(g *Graph) {}, ""},
+		{"version mismatch", func(g *Graph) { g.Version = Version + 1 }, "지원하지 않는 순서도 버전"},
+		{"no start", func(g *Graph) {
+			g.Nodes = g.Nodes[1:]
+			g.Edges = g.Edges[1:]
+		}, "시작 블록은 정확히 하나여야 합니다 (0개)"},
+		{"two starts", func(g *Graph) {
+			g.Nodes = append(g.Nodes, Node{ID: "s2", Type: Start})
+			g.Edges = append(g.Edges, Edge{From: "s2", To: "in"})
+		}, "시작 블록은 정확히 하나여야 합니다 (2개)"},
+		{"no end", func(g *Graph) {
+			g.Nodes = g.Nodes[:len(g.Nodes)-1]
+			g.Edges = g.Edges[:len(g.Edges)-1]
+		}, "끝 블록이 없습니다"},
+		{"duplicate ID", func(g *Graph) { g.Nodes = append(g.Nodes, Node{ID: "in", Type: End}) }, "ID가 중복됩니다"},
+		{"unknown type", func(g *Graph) { g.Nodes[1].Type = "loop" }, "알 수 없는 종류"},
+		{"edge to a missing node", func(g *Graph) {
+			g.Edges = append(g.Edges, Edge{From: "out", To: "nowhere"})
+		}, "없는 블록을 가리킵니다"},
+		{"edge from a missing node", func(g *Graph) {
+			g.Edges = append(g.Edges, Edge{From: "nowhere", To: "e"})
+		}, "없는 블록을 가리킵니다"},
+		{"unreachable block", func(g *Graph) {
+			g.Nodes = append(g.Nodes, Node{ID: "lost", Type: Output, Expr: "1"})
+			g.Edges = append(g.Edges, Edge{From: "lost", To: "e"})
+		}, "블록 lost: 시작 블록에서 닿을 수 없습니다"},
+		{"decision without no", func(g *Graph) { g.Edges[5].Label = "" }, "yes와 no 간선을 하나씩"},
+		{"decision with two yes", func(g *Graph) { g.Edges[5].Label = BranchYes }, "yes와 no 간선을 하나씩"},
+		{"decision with three edges", func(g *Graph) {
+			g.Edges = append(g.Edges, Edge{From: "d", To: "e", Label: BranchNo})
+		}, "yes와 no 간선을 하나씩"},
+		{"edge into start", func(g *Graph) { g.Edges[6].To = "s" }, "시작 블록으로 들어오는 간선이 있습니다"},
+		{"edge out of end", func(g *Graph) {
+			g.Edges = append(g.Edges, Edge{From: "e", To: "out"})
+		}, "끝 블록에서 나가는 간선이 있습니다"},
+		{"two edges out of a process", func(g *Graph) {
+			g.Edges = append(g.Edges, Edge{From: "init", To: "out"})
+		}, "블록 init: 나가는 간선이 하나여야 합니다 (2개)"},
+		{"bad input variable", func(g *Graph) { g.Nodes[1].Var = "1n" }, "블록 in:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := sumGraph()
+			tt.change(g)
+			err := Validate(g)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want a ValidationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
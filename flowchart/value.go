@@ -0,0 +1,210 @@
+// flowchart/value.go
+package flowchart
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 인터프리터의 값은 float64, string, bool 중 하나입니다. 컴파일한 코드와 같은
+// 결과가 나오도록 JavaScript의 형 변환 규칙을 따릅니다.
+type value interface{}
+
+// toNumber는 JavaScript의 Number(v)입니다
+func toNumber(v value) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		return parseNumber(v)
+	}
+	return math.NaN()
+}
+
+// parseNumber는 문자열을 JavaScript의 Number(s)처럼 숫자로 바꿉니다.
+// 숫자가 아니면 NaN입니다.
+func parseNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "":
+		return 0
+	case "Infinity", "+Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+	if len(s) > 2 && s[0] == '0' && strings.ContainsRune("xXoObB", rune(s[1])) {
+		n, err := strconv.ParseUint(s[2:], map[byte]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}[s[1]], 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(n)
+	}
+	// strconv는 "inf", "1_000", "0x1p3"처럼 JavaScript가 받지 않는 형식도 읽는다
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789+-.eE", r) {
+			return math.NaN()
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil && !isRangeError(err) {
+		return math.NaN()
+	}
+	return n
+}
+
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+// toBool은 JavaScript의 참/거짓 판단입니다
+func toBool(v value) bool {
+	switch v := v.(type) {
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case bool:
+		return v
+	}
+	return false
+}
+
+// toString은 JavaScript의 String(v)입니다
+func toString(v value) string {
+	switch v := v.(type) {
+	case float64:
+		return formatNumber(v)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return "undefined"
+}
+
+// formatNumber는 숫자를 JavaScript와 같은 모양의 문자열로 바꿉니다
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+
+	// 가장 짧은 자릿수와 지수를 구한 뒤 JavaScript의 규칙대로 배치한다
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	n, _ := strconv.Atoi(exp)
+	n++ // 소수점 앞 자릿수
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	exponent := strconv.Itoa(abs(n - 1))
+	if k == 1 {
+		return sign + digits + "e" + expSign + exponent
+	}
+	return sign + digits[:1] + "." + digits[1:] + "e" + expSign + exponent
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// inputValue는 입력 한 줄을 값으로 바꿉니다. 숫자로 읽을 수 있으면 숫자,
+// 아니면 문자열입니다 (컴파일한 코드의 __input과 같은 규칙).
+func inputValue(line string) value {
+	if strings.TrimSpace(line) == "" {
+		return line
+	}
+	if n := parseNumber(line); !math.IsNaN(n) {
+		return n
+	}
+	return line
+}
+
+// looseEqual은 JavaScript의 == 비교입니다
+func looseEqual(a, b value) bool {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return a == b
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return a == b
+		}
+	}
+	return toNumber(a) == toNumber(b)
+}
+
+// compare는 JavaScript의 <, <=, >, >= 비교입니다. 둘 다 문자열이면 사전순입니다.
+func compare(op string, a, b value) bool {
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			switch op {
+			case "<":
+				return as < bs
+			case "<=":
+				return as <= bs
+			case ">":
+				return as > bs
+			default:
+				return as >= bs
+			}
+		}
+	}
+	x, y := toNumber(a), toNumber(b)
+	switch op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	default:
+		return x >= y
+	}
+}
+
+// jsRound는 JavaScript의 Math.round입니다 (.5는 양의 무한대 쪽으로 올림)
+func jsRound(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+	r := math.Floor(x)
+	if x-r >= 0.5 {
+		r++
+	}
+	return r
+}
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -39,6 +40,27 @@ func generateToken(classID uint, classnum string) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+// parseToken validates a JWT token string and returns its claims
+func parseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return nil, errInvalidClaims
+	}
+	return claims, nil
+}
+
+var errInvalidClaims = errors.New("invalid token claims")
+
 // AuthMiddleware validates JWT tokens
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -49,19 +71,14 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, err := parseToken(tokenString)
+		if errors.Is(err, errInvalidClaims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := token.Claims.(*Claims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
@@ -71,3 +88,24 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware sets the class claims when a valid token is present
+// but lets unauthenticated requests through, for routes that students and
+// teachers share.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tokenString := c.GetHeader("Authorization"); tokenString != "" {
+			if claims, err := parseToken(tokenString); err == nil {
+				c.Set("class_id", claims.ClassID)
+				c.Set("classnum", claims.Classnum)
+			}
+		}
+		c.Next()
+	}
+}
+
+// isClassOwner reports whether the request is authenticated as the given class
+func isClassOwner(c *gin.Context, classID uint) bool {
+	id, exists := c.Get("class_id")
+	return exists && id.(uint) == classID
+}
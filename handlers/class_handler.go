@@ -126,7 +126,7 @@ func (h *ClassHandler) GetClass(c *gin.Context) {
 		return
 	}
 
-	if err := h.DB.Preload("Problems").First(&class, id).Error; err != nil {
+	if err := h.DB.Preload("Problems.Testcases", orderTestcases).First(&class, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Class not found"})
 		return
 	}
@@ -179,7 +179,7 @@ func (h *ClassHandler) GetClassByClassnum(c *gin.Context) {
 	classnum := c.Param("classnum")
 	var class models.Class
 
-	if err := h.DB.Preload("Problems").Where("classnum = ?", classnum).First(&class).Error; err != nil {
+	if err := h.DB.Preload("Problems.Testcases", orderTestcases).Where("classnum = ?", classnum).First(&class).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Class not found"})
 			return
@@ -188,6 +188,10 @@ func (h *ClassHandler) GetClassByClassnum(c *gin.Context) {
 		return
 	}
 
+	for i := range class.Problems {
+		hideAnswers(c, &class.Problems[i])
+	}
+
 	class.Passwd = "" // Remove password from response
 	c.JSON(http.StatusOK, class)
 }
@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"Flow-Chart-Block-Coding-Backend/judge"
 	"Flow-Chart-Block-Coding-Backend/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// errStructuredTestcases is returned when the legacy testcase strings change on a
+// problem whose testcases were edited through the testcases API
+var errStructuredTestcases = errors.New("problem has testcases managed through the testcases API; edit them there instead")
+
 // ProblemHandler handles operations on Problem model
 type ProblemHandler struct {
 	DB *gorm.DB
@@ -24,10 +31,41 @@ func (h *ProblemHandler) CreateProblem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !isClassOwner(c, problem.ClassID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to create problems for this class"})
+		return
+	}
+	if problem.Constraints != nil {
+		if err := problem.Constraints.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if _, err := judge.NewChecker(problem.Checker); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateReference(&problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if problem.MaxScore < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Max score must not be negative"})
+		return
+	}
+	if err := validateLimits(&problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 예전 형식의 문자열만 보낸 경우 테스트케이스로 변환
+	if len(problem.Testcases) == 0 && problem.TestcaseInput != "" {
+		problem.Testcases = models.ParseLegacyTestcases(problem.TestcaseInput, problem.TestcaseOutput)
+	}
 	if err := h.DB.Create(&problem).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create problem"})
 		return
 	}
+	hideAnswers(c, &problem)
 	c.JSON(http.StatusCreated, problem)
 }
 
@@ -38,10 +76,11 @@ func (h *ProblemHandler) GetProblem(c *gin.Context) {
 		return
 	}
 	var problem models.Problem
-	if err := h.DB.First(&problem, uint(id)).Error; err != nil {
+	if err := h.DB.Preload("Testcases", orderTestcases).First(&problem, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
 		return
 	}
+	hideAnswers(c, &problem)
 	c.JSON(http.StatusOK, problem)
 }
 
@@ -56,11 +95,93 @@ func (h *ProblemHandler) UpdateProblem(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
 		return
 	}
+	if !isClassOwner(c, problem.ClassID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this problem"})
+		return
+	}
+	legacyInput, legacyOutput := problem.TestcaseInput, problem.TestcaseOutput
 	if err := c.ShouldBindJSON(&problem); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	h.DB.Save(&problem)
+	// 본문의 ID로 다른 문제를 덮어쓰지 못하게 한다
+	problem.ID = uint(id)
+	// 다른 클래스로 옮길 수도 없다
+	if !isClassOwner(c, problem.ClassID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to move this problem to another class"})
+		return
+	}
+	// 테스트케이스는 예전 형식의 문자열이나 테스트케이스 API로만 바꾼다
+	if len(problem.Testcases) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Edit testcases through the testcases API or the legacy testcase strings"})
+		return
+	}
+	legacyChanged := problem.TestcaseInput != legacyInput || problem.TestcaseOutput != legacyOutput
+	if problem.Constraints != nil {
+		if err := problem.Constraints.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if _, err := judge.NewChecker(problem.Checker); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateReference(&problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if problem.MaxScore < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Max score must not be negative"})
+		return
+	}
+	if err := validateLimits(&problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Testcases").Save(&problem).Error; err != nil {
+			return err
+		}
+		// 예전 형식의 문자열이 바뀐 경우 테스트케이스를 다시 만든다. 테스트케이스
+		// API로 만든 케이스는 지우지 않는다.
+		if !legacyChanged {
+			return nil
+		}
+		var existing []models.Testcase
+		if err := orderTestcases(tx.Where("problem_id = ?", problem.ID)).Find(&existing).Error; err != nil {
+			return err
+		}
+		if !models.IsLegacyTestcases(existing, legacyInput, legacyOutput) {
+			return errStructuredTestcases
+		}
+		if err := tx.Where("problem_id = ?", problem.ID).Delete(&models.Testcase{}).Error; err != nil {
+			return err
+		}
+		testcases := models.ParseLegacyTestcases(problem.TestcaseInput, problem.TestcaseOutput)
+		if len(testcases) == 0 {
+			return nil
+		}
+		for i := range testcases {
+			testcases[i].ProblemID = problem.ID
+		}
+		return tx.Create(&testcases).Error
+	})
+	if errors.Is(err, errStructuredTestcases) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update problem"})
+		return
+	}
+	// 다시 만든 테스트케이스까지 저장된 그대로 돌려준다
+	if err := h.DB.Preload("Testcases", orderTestcases).First(&problem, problem.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load problem"})
+		return
+	}
+	hideAnswers(c, &problem)
 	c.JSON(http.StatusOK, problem)
 }
 
@@ -70,7 +191,16 @@ func (h *ProblemHandler) DeleteProblem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
 		return
 	}
-	if err := h.DB.Delete(&models.Problem{}, uint(id)).Error; err != nil {
+	var problem models.Problem
+	if err := h.DB.First(&problem, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return
+	}
+	if !isClassOwner(c, problem.ClassID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this problem"})
+		return
+	}
+	if err := h.DB.Delete(&problem).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete problem"})
 		return
 	}
@@ -83,6 +213,82 @@ func (h *ProblemHandler) ListProblems(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list problems"})
 		return
 	}
+	for i := range problems {
+		hideAnswers(c, &problems[i])
+	}
 	c.JSON(http.StatusOK, problems)
 }
 
+// ListScores returns every user's best score on the problem, highest first.
+// Only the class that owns the problem can see the scores.
+func (h *ProblemHandler) ListScores(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var problem models.Problem
+	if err := h.DB.First(&problem, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return
+	}
+	if !isClassOwner(c, problem.ClassID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this problem"})
+		return
+	}
+
+	var scores []models.BestScore
+	if err := h.DB.Where("problem_id = ?", problem.ID).Order("score DESC, user_name").Find(&scores).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list scores"})
+		return
+	}
+	c.JSON(http.StatusOK, scores)
+}
+
+// orderTestcases sorts preloaded testcases in grading order
+func orderTestcases(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+// hideAnswers strips hidden testcases, the legacy answer strings, the reference
+// solution and the checker script from a problem unless the request is
+// authenticated as the class that owns it.
+func hideAnswers(c *gin.Context, problem *models.Problem) {
+	if isClassOwner(c, problem.ClassID) {
+		return
+	}
+
+	problem.TestcaseInput = ""
+	problem.TestcaseOutput = ""
+	problem.ReferenceCode = ""
+	problem.ReferenceFlowchart = nil
+	if problem.Checker != nil && problem.Checker.Script != "" {
+		checker := *problem.Checker
+		checker.Script = ""
+		problem.Checker = &checker
+	}
+
+	samples := make([]models.Testcase, 0, len(problem.Testcases))
+	for _, tc := range problem.Testcases {
+		if tc.IsSample {
+			samples = append(samples, tc)
+		}
+	}
+	problem.Testcases = samples
+}
+
+// validateLimits checks that the problem's limits are not negative and do not
+// exceed the server-wide maximums
+func validateLimits(problem *models.Problem) error {
+	switch {
+	case problem.TimeLimit < 0 || int64(problem.TimeLimit) > maxLimits.Timeout.Milliseconds():
+		return fmt.Errorf("Time limit must be between 0 and %d ms", maxLimits.Timeout.Milliseconds())
+	case problem.MemoryLimit < 0 || uint64(problem.MemoryLimit) > maxLimits.MemoryBytes>>20:
+		return fmt.Errorf("Memory limit must be between 0 and %d MB", maxLimits.MemoryBytes>>20)
+	case problem.OutputLineLimit < 0 || problem.OutputLineLimit > maxLimits.OutputLines:
+		return fmt.Errorf("Output line limit must be between 0 and %d", maxLimits.OutputLines)
+	case problem.OutputByteLimit < 0 || problem.OutputByteLimit > maxLimits.OutputBytes:
+		return fmt.Errorf("Output byte limit must be between 0 and %d bytes", maxLimits.OutputBytes)
+	}
+	return nil
+}
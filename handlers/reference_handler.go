@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	"Flow-Chart-Block-Coding-Backend/flowchart"
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"Flow-Chart-Block-Coding-Backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// validateReference는 문제에 저장할 참조 풀이가 실행할 수 있는 모양인지, 무작위
+// 테스트케이스 생성기가 올바르고 정답을 만들 참조 풀이가 있는지 확인합니다.
+func validateReference(problem *models.Problem) error {
+	if problem.ReferenceFlowchart != nil {
+		if err := flowchart.Validate(problem.ReferenceFlowchart); err != nil {
+			return err
+		}
+	} else if problem.ReferenceCode != "" {
+		if err := judge.Script(problem.ReferenceCode).Compile(); err != nil {
+			return err
+		}
+	}
+	if problem.Generator == nil {
+		return nil
+	}
+	if referenceExecutor(problem) == nil {
+		return errors.New("무작위 테스트케이스를 만들려면 참조 풀이가 필요합니다")
+	}
+	_, err := judge.NewGenerator(problem.Generator)
+	return err
+}
+
+// referenceExecutor는 문제의 참조 풀이를 실행하는 Executor입니다. 순서도가 있으면
+// 인터프리터로, 없으면 코드를 실행합니다. 참조 풀이가 없으면 nil을 반환합니다.
+func referenceExecutor(problem *models.Problem) judge.Executor {
+	if problem.ReferenceFlowchart != nil {
+		return flowchart.NewInterpreter(problem.ReferenceFlowchart)
+	}
+	if problem.ReferenceCode != "" {
+		return judge.Script(problem.ReferenceCode)
+	}
+	return nil
+}
+
+// runReference runs the problem's reference solution over all of its
+// testcases, judged against the stored expected outputs with the problem's
+// checker. It writes the error response itself.
+func (h *TestcaseHandler) runReference(c *gin.Context, problem *models.Problem) ([]models.Testcase, []judge.TestResult, bool) {
+	exec := referenceExecutor(problem)
+	if exec == nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Problem has no reference solution"})
+		return nil, nil, false
+	}
+	check, err := judge.NewChecker(problem.Checker)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	var testcases []models.Testcase
+	if err := h.DB.Scopes(orderTestcases).Where("problem_id = ?", problem.ID).Find(&testcases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list testcases"})
+		return nil, nil, false
+	}
+
+	cases := make([]judge.TestCase, len(testcases))
+	for i, tc := range testcases {
+		cases[i] = judgeCase(problem, &tc)
+	}
+	results, err := h.Judge.Run(c.Request.Context(), exec, cases, problemLimits(problem), check)
+	if err != nil {
+		if errors.Is(err, judge.ErrQueueFull) {
+			respondQueueFull(c)
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run reference solution"})
+		return nil, nil, false
+	}
+	return testcases, results, true
+}
+
+// fillExpectedOutput sets the expected output of a testcase sent without one
+// to the reference solution's output. It writes the error response itself.
+func (h *TestcaseHandler) fillExpectedOutput(c *gin.Context, problem *models.Problem, testcase *models.Testcase) bool {
+	if len(testcase.Output) > 0 {
+		return true
+	}
+	exec := referenceExecutor(problem)
+	if exec == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected output is required"})
+		return false
+	}
+
+	results, err := h.Judge.Run(c.Request.Context(), exec, []judge.TestCase{judgeCase(problem, testcase)}, problemLimits(problem), nil)
+	if err != nil {
+		if errors.Is(err, judge.ErrQueueFull) {
+			respondQueueFull(c)
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run reference solution"})
+		return false
+	}
+	r := results[0]
+	if !r.Verdict.Completed() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Reference solution failed on this testcase",
+			"verdict": r.Verdict,
+			"message": r.Message,
+		})
+		return false
+	}
+	testcase.Output = r.Output
+	if testcase.Output == nil {
+		testcase.Output = []string{}
+	}
+	return true
+}
+
+// VerifyTestcases runs the reference solution over every testcase and reports
+// the cases where its output disagrees with the stored expected output.
+func (h *TestcaseHandler) VerifyTestcases(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+	testcases, results, ok := h.runReference(c, problem)
+	if !ok {
+		return
+	}
+
+	report := make([]gin.H, len(testcases))
+	disagreements := 0
+	for i, tc := range testcases {
+		r := results[i]
+		agrees := r.Verdict == judge.Accepted
+		if !agrees {
+			disagreements++
+		}
+		report[i] = gin.H{
+			"testcaseId": tc.ID,
+			"name":       tc.Name,
+			"input":      tc.Input,
+			"expected":   tc.Output,
+			"actual":     r.Output,
+			"verdict":    r.Verdict,
+			"message":    r.Message,
+			"error":      errorText(r.Error),
+			"agrees":     agrees,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total":         len(testcases),
+		"disagreements": disagreements,
+		"results":       report,
+	})
+}
+
+// GenerateTestcaseOutputs replaces the expected output of every testcase with
+// the reference solution's output. Testcases on which the reference solution
+// fails (compile error, runtime error, limits) are left unchanged and reported.
+func (h *TestcaseHandler) GenerateTestcaseOutputs(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+	testcases, results, ok := h.runReference(c, problem)
+	if !ok {
+		return
+	}
+
+	var updated []models.Testcase
+	var failed []gin.H
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range testcases {
+			tc, r := &testcases[i], results[i]
+			if !r.Verdict.Completed() {
+				failed = append(failed, gin.H{
+					"testcaseId": tc.ID,
+					"name":       tc.Name,
+					"verdict":    r.Verdict,
+					"message":    r.Message,
+					"error":      errorText(r.Error),
+				})
+				continue
+			}
+			tc.Output = r.Output
+			if tc.Output == nil {
+				tc.Output = []string{}
+			}
+			if err := tx.Model(&models.Testcase{ID: tc.ID}).Select("Output").Updates(models.Testcase{Output: tc.Output}).Error; err != nil {
+				return err
+			}
+			updated = append(updated, *tc)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update testcases"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+		"failed":  failed,
+	})
+}
+
+// newSeed는 제출마다 무작위 테스트케이스를 만들 시드를 고릅니다. JavaScript에서도
+// 정확히 다룰 수 있도록 1 이상 2^53 미만으로 고릅니다.
+func newSeed() int64 {
+	return rand.Int63n(1<<53-1) + 1
+}
+
+// generatedCaseID는 index번째 무작위 테스트케이스의 ID입니다. 저장된 테스트케이스와
+// 겹치지 않도록 -1, -2, ... 를 씁니다.
+func generatedCaseID(index int) int {
+	return -(index + 1)
+}
+
+// generatedCases는 seed로 문제의 무작위 테스트케이스를 만들고, 참조 풀이를 실행해
+// 정답을 채웁니다. 생성기가 없으면 nil을 반환합니다. 참조 풀이가 어떤 케이스에서
+// 실패하면 학생의 잘못이 아니므로 에러를 반환합니다.
+func generatedCases(ctx context.Context, judgeService *judge.Judge, problem *models.Problem, seed int64) ([]judge.TestCase, error) {
+	if problem.Generator == nil {
+		return nil, nil
+	}
+	exec := referenceExecutor(problem)
+	if exec == nil {
+		return nil, errors.New("참조 풀이가 없습니다")
+	}
+	inputs, err := judgeService.Generate(ctx, problem.Generator, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]judge.TestCase, len(inputs))
+	for i, input := range inputs {
+		cases[i] = problemCase(problem, generatedCaseID(i), input)
+		cases[i].Points = problem.Generator.Points
+	}
+	results, err := judgeService.Run(ctx, exec, cases, problemLimits(problem), nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range results {
+		if !r.Verdict.Completed() {
+			return nil, fmt.Errorf("참조 풀이가 무작위 테스트케이스 #%d에서 실패했습니다 (%s: %s)", i+1, r.Verdict, r.Message)
+		}
+		cases[i].Output = r.Output
+		if cases[i].Output == nil {
+			cases[i].Output = []string{}
+		}
+	}
+	return cases, nil
+}
+
+// errorText returns the error's message, or "" for nil, since an error value
+// inside gin.H is encoded as an empty JSON object
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"Flow-Chart-Block-Coding-Backend/flowchart"
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"Flow-Chart-Block-Coding-Backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 실행해 보기에 넣을 수 있는 입력 줄 수
+const maxRunInputLines = 1000
+
+// maxCodeRequestBytes는 코드나 순서도를 받는 요청 본문의 최대 크기입니다
+const maxCodeRequestBytes = 1 << 20
+
+// LimitCodeRequest는 요청 본문을 maxCodeRequestBytes까지만 읽게 합니다. 넘으면
+// JSON 바인딩이 실패해 잘못된 요청으로 응답합니다.
+func LimitCodeRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCodeRequestBytes)
+		c.Next()
+	}
+}
+
+type RunRequest struct {
+	Code      string           `json:"code"`
+	Flowchart *flowchart.Graph `json:"flowchart"` // 보내면 code 대신 순서도를 실행
+	Engine    string           `json:"engine"`    // 순서도 실행 방법: "interpreter"(기본값) 또는 "javascript"
+	Input     []string         `json:"input"`     // 한 줄씩 prompt()로 전달
+	ProblemID uint             `json:"problemId"` // 보내면 그 문제의 제한과 실행 환경으로 실행
+}
+
+// RunHandler는 제출하기 전에 코드나 순서도를 직접 넣은 입력으로 한 번 실행해 봅니다.
+// 채점과 같은 샌드박스와 제한을 쓰지만 출력을 비교하지 않고, 아무것도 기록하지 않습니다.
+func RunHandler(db *gorm.DB, judgeService *judge.Judge) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RunRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 요청 형식입니다",
+			})
+			return
+		}
+		if len(req.Input) > maxRunInputLines {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "입력이 너무 깁니다",
+			})
+			return
+		}
+
+		engine, err := compileSubmission(&req.Code, req.Flowchart, req.Engine)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+
+		var problem models.Problem
+		if req.ProblemID != 0 {
+			if err := db.First(&problem, req.ProblemID).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{
+					"success": false,
+					"message": "존재하지 않는 문제입니다",
+				})
+				return
+			}
+		}
+
+		exec := submissionExecutor(&models.Submission{Code: req.Code, Flowchart: req.Flowchart, Engine: engine})
+		run, err := judgeService.Execute(c.Request.Context(), exec, problemCase(&problem, 0, req.Input), problemLimits(&problem))
+		if errors.Is(err, judge.ErrQueueFull) {
+			respondQueueFull(c)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "실행에 실패했습니다",
+			})
+			return
+		}
+
+		result := run.Result
+		if result.Output == nil {
+			result.Output = []string{} // null 대신 빈 배열로 응답한다
+		}
+		data := gin.H{
+			"verdict":         result.Verdict,
+			"output":          result.Output,
+			"promptsConsumed": run.InputsRead,
+			"elapsedMs":       float64(result.Elapsed) / float64(time.Millisecond),
+			"engine":          engine,
+		}
+		if result.Verdict != judge.Accepted {
+			data["error"] = resultMessage(result)
+			if result.Node != "" {
+				data["node"] = result.Node
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    data,
+		})
+	}
+}
@@ -3,25 +3,31 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"Flow-Chart-Block-Coding-Backend/flowchart"
 	"Flow-Chart-Block-Coding-Backend/judge"  // 프로젝트 경로에 맞게 수정
 	"Flow-Chart-Block-Coding-Backend/models" // 프로젝트 경로에 맞게 수정
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CodeSubmission struct {
-	Code      string `json:"code"`
-	Username  string `json:"username"`
-	ProblemID uint   `json:"problemId"`
+	Code      string           `json:"code"`
+	Flowchart *flowchart.Graph `json:"flowchart"` // 보내면 code 대신 순서도를 서버에서 컴파일해 채점
+	Username  string           `json:"username"`
+	ProblemID uint             `json:"problemId"`
+	Async     bool             `json:"async"`  // true면 채점을 기다리지 않고 제출 ID를 바로 반환
+	Engine    string           `json:"engine"` // 순서도 실행 방법: "interpreter"(기본값) 또는 "javascript"
 }
 
-func SolvedHandler(db *gorm.DB) gin.HandlerFunc {
+func SolvedHandler(db *gorm.DB, judgeService *judge.Judge) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var submission CodeSubmission
 		if err := c.ShouldBindJSON(&submission); err != nil {
@@ -32,6 +38,15 @@ func SolvedHandler(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		engine, err := compileSubmission(&submission.Code, submission.Flowchart, submission.Engine)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+
 		// 사용자 확인
 		var user models.User
 		if err := db.Where("name = ?", submission.Username).First(&user).Error; err != nil {
@@ -52,87 +67,359 @@ func SolvedHandler(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// '/' 구분자로 테스트케이스 분리
-		testCaseGroups := strings.Split(strings.TrimSpace(problem.TestcaseInput), "/")
-		outputs := strings.Split(strings.TrimSpace(problem.TestcaseOutput), "/")
-
 		// 테스트케이스 준비
-		var testCases []judge.TestCase
-		for i := 0; i < len(testCaseGroups); i++ {
-			// 각 테스트케이스의 입력값들을 공백으로 분리
-			inputs := strings.Fields(testCaseGroups[i])
-			if len(inputs) < 2 {
-				continue
-			}
+		var testcases []models.Testcase
+		if err := db.Scopes(orderTestcases).Where("problem_id = ?", problem.ID).Find(&testcases).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "테스트케이스를 불러오지 못했습니다",
+			})
+			return
+		}
+		if len(testcases) == 0 && problem.Generator == nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"success": false,
+				"message": "채점할 테스트케이스가 없는 문제입니다",
+			})
+			return
+		}
+
+		testCases := make([]judge.TestCase, len(testcases))
+		for i, tc := range testcases {
+			testCases[i] = judgeCase(&problem, &tc)
+		}
+
+		// 무작위 테스트케이스는 제출마다 새로 만들고, 다시 만들 수 있도록 시드를 기록한다
+		var seed int64
+		if problem.Generator != nil {
+			seed = newSeed()
+		}
+
+		record := models.Submission{
+			ProblemID: problem.ID,
+			UserID:    user.ID,
+			UserName:  user.Name,
+			Code:      submission.Code,
+			Flowchart: submission.Flowchart,
+			Engine:    engine,
+			CaseCount: len(testCases),
+			Seed:      seed,
+			MaxScore:  submissionMaxScore(&problem, testCases),
+		}
+		limits := problemLimits(&problem)
+		check, err := judge.NewChecker(problem.Checker)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "문제의 채점 방식 설정이 잘못되었습니다",
+			})
+			return
+		}
+		if submission.Async {
+			submitAsync(c, db, judgeService, gradingExecutor(&record, &problem), record, &user, &problem, testCases, limits, check)
+			return
+		}
 
-			testCase := judge.TestCase{
-				ID:     i,
-				Input:  []string{inputs[0], inputs[1]},
-				Output: []string{strings.TrimSpace(outputs[i])},
+		if problem.Generator != nil {
+			generated, err := generatedCases(c.Request.Context(), judgeService, &problem, seed)
+			if errors.Is(err, judge.ErrQueueFull) {
+				respondQueueFull(c)
+				return
+			}
+			if err != nil {
+				log.Printf("problem %d: failed to generate testcases: %v", problem.ID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": "무작위 테스트케이스를 만들지 못했습니다",
+				})
+				return
 			}
-			testCases = append(testCases, testCase)
+			testCases = append(testCases, generated...)
+			record.CaseCount = len(testCases)
+			record.MaxScore = submissionMaxScore(&problem, testCases)
 		}
 
-		// judge 생성 및 테스트 실행
-		judgeSystem := judge.NewJudge(2*time.Second, 4)
-		results := judgeSystem.RunTestsParallel(submission.Code, testCases)
+		// 테스트 실행
+		results, err := judgeService.Run(c.Request.Context(), gradingExecutor(&record, &problem), testCases, limits, check)
+		if errors.Is(err, judge.ErrQueueFull) {
+			respondQueueFull(c)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "채점에 실패했습니다",
+			})
+			return
+		}
+		if c.Request.Context().Err() != nil {
+			return // 클라이언트가 연결을 끊은 경우 기록하지 않는다
+		}
 
 		// 결과 확인
-		allPassed := true
+		verdict := judge.Overall(results)
+		allPassed := verdict == judge.Accepted
+		// 모든 테스트케이스의 결과를 보여주고, 메시지는 처음 실패한 케이스로 만든다
+		reports := make([]gin.H, len(results))
+		passed := 0
 		var failedMessage string
-		for _, result := range results {
-			if !result.Passed {
-				allPassed = false
-				if result.Error != nil {
-					failedMessage = result.Error.Error()
-				} else {
-					failedMessage = result.Message
-				}
-				break
+		for i, result := range results {
+			sample := i < len(testcases) && testcases[i].IsSample
+			visible := visibleResult(c, &problem, sample, i+1, result)
+			reports[i] = caseReport(c, &problem, sample, testCases[i], visible)
+			if result.Passed {
+				passed++
+			} else if failedMessage == "" {
+				failedMessage = resultMessage(visible)
 			}
 		}
-
-		if !allPassed {
+		respond := func(success bool, message string) {
 			c.JSON(http.StatusOK, gin.H{
+				"success":      success,
+				"message":      message,
+				"verdict":      verdict,
+				"score":        record.Score,
+				"maxScore":     record.MaxScore,
+				"submissionId": record.ID,
+				"results":      reports,
+				"summary": gin.H{
+					"passed": passed,
+					"total":  len(results),
+				},
+			})
+		}
+
+		// 제출 기록 저장
+		record.Status = models.SubmissionFinished
+		record.Verdict = verdict
+		record.Score = judge.Score(testCases, results, record.MaxScore)
+		record.Results = results
+		if err := db.Create(&record).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
-				"message": failedMessage,
+				"message": "제출 기록 저장에 실패했습니다",
 			})
 			return
 		}
-
-		// 이미 해결했는지 확인
-		var existingSolved models.Solved
-		err := db.Where("problem_id = ? AND user_id = ?",
-			submission.ProblemID, user.ID).First(&existingSolved).Error
-		if err == nil {
-			c.JSON(http.StatusOK, gin.H{
-				"success": true,
-				"message": "이미 해결한 문제입니다",
+		if err := recordBestScore(db, &user, &record); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "점수 저장에 실패했습니다",
 			})
 			return
 		}
 
-		// solved 테이블에 추가
-		solved := models.Solved{
-			ProblemID: submission.ProblemID,
-			UserID:    user.ID,
-			UserName:  user.Name,
-			// SolvedAt:  time.Now(),
+		if !allPassed {
+			respond(false, failedMessage)
+			return
 		}
 
-		if err := db.Create(&solved).Error; err != nil {
+		alreadySolved, err := recordSolved(db, &user, problem.ID)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"message": "채점 결과 저장에 실패했습니다",
 			})
 			return
 		}
+		if alreadySolved {
+			respond(true, "이미 해결한 문제입니다")
+			return
+		}
+		respond(true, "문제를 성공적으로 해결했습니다")
+	}
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "문제를 성공적으로 해결했습니다",
-		})
+// compileSubmission은 순서도를 보냈으면 서버에서 코드로 바꿔 *code에 넣고, 실행 방법을
+// 정합니다. 순서도는 기본으로 직접 실행하고, 순서도가 없으면 코드를 실행합니다.
+func compileSubmission(code *string, graph *flowchart.Graph, engine string) (string, error) {
+	if graph == nil {
+		return models.EngineJavaScript, nil
+	}
+	switch engine {
+	case "":
+		engine = models.EngineInterpreter
+	case models.EngineInterpreter, models.EngineJavaScript:
+	default:
+		return "", errors.New("지원하지 않는 실행 방법입니다")
+	}
+
+	compiled, err := flowchart.Compile(graph)
+	if err != nil {
+		return "", err
+	}
+	*code = compiled
+	return engine, nil
+}
+
+// recordSolved는 문제를 처음 해결한 경우 solved 테이블에 추가합니다.
+// 이미 해결한 문제였다면 true를 반환합니다.
+func recordSolved(db *gorm.DB, user *models.User, problemID uint) (bool, error) {
+	// 이미 해결했는지 확인
+	var existingSolved models.Solved
+	err := db.Where("problem_id = ? AND user_id = ?",
+		problemID, user.ID).First(&existingSolved).Error
+	if err == nil {
+		return true, nil
+	}
+
+	// solved 테이블에 추가
+	solved := models.Solved{
+		ProblemID: problemID,
+		UserID:    user.ID,
+		UserName:  user.Name,
+		// SolvedAt:  time.Now(),
+	}
+	return false, db.Create(&solved).Error
+}
+
+// judgeCase는 저장된 테스트케이스를 채점할 테스트케이스로 바꿉니다. Math.random
+// 시드와 Date 시각은 테스트케이스에 지정된 값이 문제의 값보다 앞섭니다.
+func judgeCase(problem *models.Problem, tc *models.Testcase) judge.TestCase {
+	c := problemCase(problem, int(tc.ID), tc.Input)
+	c.Output = tc.Output
+	c.Points = tc.Points
+	if tc.RandomSeed != 0 {
+		c.Seed = tc.RandomSeed
+	}
+	if tc.FixedTime != nil {
+		c.Now = *tc.FixedTime
+	}
+	return c
+}
+
+// problemCase는 문제의 실행 환경(Math.random 시드, Date 시각)으로 테스트케이스를 만듭니다.
+func problemCase(problem *models.Problem, id int, input []string) judge.TestCase {
+	c := judge.TestCase{ID: id, Input: input, Seed: problem.RandomSeed}
+	if problem.FixedTime != nil {
+		c.Now = *problem.FixedTime
+	}
+	return c
+}
+
+// caseReport는 제출 응답에 담을 테스트케이스 하나의 결과입니다. 예제 테스트케이스는
+// 입력, 정답, 실제 출력도 함께 보여줍니다. result는 visibleResult를 거친 결과입니다.
+func caseReport(c *gin.Context, problem *models.Problem, sample bool, tc judge.TestCase, result judge.TestResult) gin.H {
+	report := gin.H{
+		"testCaseId": result.TestCaseID,
+		"verdict":    result.Verdict,
+		"passed":     result.Passed,
+		"message":    resultMessage(result),
+		"elapsedMs":  float64(result.Elapsed) / float64(time.Millisecond),
+		"sample":     sample,
+	}
+	if sample || isClassOwner(c, problem.ClassID) {
+		report["input"] = tc.Input
+		report["expected"] = tc.Output
+		report["actual"] = result.Output
+		if result.Node != "" {
+			report["node"] = result.Node
+		}
+	}
+	return report
+}
+
+// submissionMaxScore는 제출의 만점입니다. 문제에 만점이 정해져 있지 않으면
+// 테스트케이스 배점의 합을 만점으로 씁니다.
+func submissionMaxScore(problem *models.Problem, cases []judge.TestCase) float64 {
+	if problem.MaxScore > 0 {
+		return float64(problem.MaxScore)
+	}
+	return float64(judge.TotalPoints(cases))
+}
+
+// recordBestScore는 제출의 점수가 사용자가 그 문제에서 받은 최고 점수보다 높으면
+// best_scores 테이블을 갱신합니다. 같은 사용자의 제출이 동시에 끝나도 한 줄만
+// 남도록 upsert 한 번으로 처리합니다. MySQL은 SET을 왼쪽부터 계산하므로 score는
+// 마지막에 바꿉니다.
+func recordBestScore(db *gorm.DB, user *models.User, submission *models.Submission) error {
+	higher := "VALUES(score) > score"
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "problem_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "max_score"}, Value: gorm.Expr("IF(" + higher + ", VALUES(max_score), max_score)")},
+			{Column: clause.Column{Name: "submission_id"}, Value: gorm.Expr("IF(" + higher + ", VALUES(submission_id), submission_id)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("IF(" + higher + ", VALUES(updated_at), updated_at)")},
+			{Column: clause.Column{Name: "score"}, Value: gorm.Expr("GREATEST(score, VALUES(score))")},
+		},
+	}).Create(&models.BestScore{
+		ProblemID:    submission.ProblemID,
+		UserID:       user.ID,
+		UserName:     user.Name,
+		Score:        submission.Score,
+		MaxScore:     submission.MaxScore,
+		SubmissionID: submission.ID,
+	}).Error
+}
+
+// respondQueueFull은 채점 대기열이 가득 찼을 때의 응답을 보냅니다.
+func respondQueueFull(c *gin.Context) {
+	c.Header("Retry-After", "5")
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"success": false,
+		"message": "채점 대기 중인 제출이 너무 많습니다. 잠시 후 다시 시도해 주세요",
+	})
+}
+
+// 순서도 인터프리터가 실행할 수 있는 블록 수
+const defaultStepLimit = 1000000
+
+// 문제에 제한이 지정되지 않았을 때 쓰는 기본값과 문제에 지정할 수 있는 최댓값.
+// 서버를 시작할 때 SetJudgeLimits로 config.json의 값을 넣습니다.
+var defaultLimits, maxLimits judge.Limits
+
+// SetJudgeLimits sets the default and maximum per-problem judge limits
+func SetJudgeLimits(defaults, caps judge.Limits) {
+	defaultLimits, maxLimits = defaults, caps
+}
+
+// problemLimits는 문제에 설정된 제한(없으면 기본값)으로 채점 제한을 만듭니다.
+// 문제의 제한은 최댓값을 넘지 않습니다.
+func problemLimits(problem *models.Problem) judge.Limits {
+	limits := defaultLimits
+	limits.Steps = defaultStepLimit
+	if problem.TimeLimit > 0 {
+		limits.Timeout = min(time.Duration(problem.TimeLimit)*time.Millisecond, maxLimits.Timeout)
+	}
+	if problem.MemoryLimit > 0 {
+		limits.MemoryBytes = min(uint64(problem.MemoryLimit)<<20, maxLimits.MemoryBytes)
+	}
+	if problem.OutputLineLimit > 0 {
+		limits.OutputLines = min(problem.OutputLineLimit, maxLimits.OutputLines)
+	}
+	if problem.OutputByteLimit > 0 {
+		limits.OutputBytes = min(problem.OutputByteLimit, maxLimits.OutputBytes)
+	}
+	return limits
+}
+
+// submissionExecutor는 제출을 기록된 실행 방법으로 실행하는 Executor를 만듭니다.
+func submissionExecutor(s *models.Submission) judge.Executor {
+	if s.Engine == models.EngineInterpreter && s.Flowchart != nil {
+		return flowchart.NewInterpreter(s.Flowchart)
+	}
+	return judge.Script(s.Code)
+}
+
+// gradingExecutor는 채점용 Executor입니다. 문제에 구조 제한이 있으면 실행하기 전에
+// 순서도(없으면 코드)의 모양을 확인해, 어기면 ConstraintViolation으로 판정합니다.
+func gradingExecutor(s *models.Submission, problem *models.Problem) judge.Executor {
+	exec := submissionExecutor(s)
+	if problem.Constraints.Empty() {
+		return exec
+	}
+
+	var structure *judge.Structure
+	var err error
+	if s.Flowchart != nil {
+		structure, err = flowchart.Analyze(s.Flowchart)
+	} else {
+		structure, err = judge.AnalyzeScript(s.Code)
+	}
+	if err != nil {
+		return exec // 문법 오류는 Compile에서 CompileError로 판정된다
 	}
+	return judge.Constrained(exec, *problem.Constraints, structure)
 }
 
 // handlers/solved_handler.go에 다음 두 함수를 추가합니다.
@@ -0,0 +1,169 @@
+// handlers/submission_events.go
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"Flow-Chart-Block-Coding-Backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// eventBufferSize는 구독자 한 명에게 쌓아둘 수 있는 결과 수입니다. 넘치는 결과는
+// 버리고, 채점이 끝났을 때 DB에 저장된 결과로 채워서 보냅니다.
+const eventBufferSize = 64
+
+// eventHub는 백그라운드 채점 결과를 SSE로 구독 중인 요청에 나눠 줍니다.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan judge.TestResult]struct{}
+}
+
+// submissionEvents는 trackSubmission이 결과를 알리는 전역 허브입니다
+var submissionEvents = &eventHub{subs: make(map[uint]map[chan judge.TestResult]struct{})}
+
+func (h *eventHub) subscribe(submissionID uint) chan judge.TestResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan judge.TestResult, eventBufferSize)
+	if h.subs[submissionID] == nil {
+		h.subs[submissionID] = make(map[chan judge.TestResult]struct{})
+	}
+	h.subs[submissionID][ch] = struct{}{}
+	return ch
+}
+
+func (h *eventHub) unsubscribe(submissionID uint, ch chan judge.TestResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs[submissionID], ch)
+	if len(h.subs[submissionID]) == 0 {
+		delete(h.subs, submissionID)
+	}
+}
+
+// publish는 채점이 끝난 테스트케이스 결과를 구독자에게 보냅니다. 채점을 막지 않도록
+// 버퍼가 찬 구독자에게는 보내지 않습니다.
+func (h *eventHub) publish(submissionID uint, r judge.TestResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[submissionID] {
+		select {
+		case ch <- r:
+		default:
+		}
+	}
+}
+
+// finish는 제출의 채점이 끝났음을 알리고 구독자 채널을 닫습니다.
+func (h *eventHub) finish(submissionID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[submissionID] {
+		close(ch)
+	}
+	delete(h.subs, submissionID)
+}
+
+// SubmissionEvents는 제출의 테스트케이스 결과를 끝나는 대로 Server-Sent Events로
+// 보냅니다. 테스트케이스마다 "result" 이벤트를, 채점이 끝나면 "summary" 이벤트를
+// 보내고 연결을 닫습니다. 이미 끝난 결과는 연결 직후 한꺼번에 보냅니다.
+func SubmissionEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submissionID, err := strconv.ParseUint(c.Param("submission_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 제출 ID 형식입니다",
+			})
+			return
+		}
+
+		// DB를 읽는 사이에 끝난 결과를 놓치지 않도록 먼저 구독한다
+		events := submissionEvents.subscribe(uint(submissionID))
+		defer submissionEvents.unsubscribe(uint(submissionID), events)
+
+		var submission models.Submission
+		if err := db.First(&submission, uint(submissionID)).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 제출입니다",
+			})
+			return
+		}
+
+		var problem models.Problem
+		if err := db.Preload("Testcases", orderTestcases).First(&problem, submission.ProblemID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 문제입니다",
+			})
+			return
+		}
+		visible := resultVisibility(c, &problem)
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no") // 프록시 버퍼링 방지
+
+		sent := make(map[int]bool)
+		sendResults := func(results []judge.TestResult) {
+			for _, r := range results {
+				if sent[r.TestCaseID] {
+					continue
+				}
+				sent[r.TestCaseID] = true
+				c.SSEvent("result", visible(r))
+			}
+			c.Writer.Flush()
+		}
+		sendSummary := func() {
+			passed := 0
+			for _, r := range submission.Results {
+				if r.Passed {
+					passed++
+				}
+			}
+			c.SSEvent("summary", gin.H{
+				"submissionId": submission.ID,
+				"status":       submission.Status,
+				"verdict":      submission.Verdict,
+				"passed":       passed,
+				"total":        submission.CaseCount,
+			})
+			c.Writer.Flush()
+		}
+
+		sendResults(submission.Results)
+		if submission.Status == models.SubmissionFinished || submission.Status == models.SubmissionCanceled {
+			sendSummary()
+			return
+		}
+
+		for {
+			select {
+			case r, ok := <-events:
+				if !ok {
+					// 채점 완료: 버퍼가 넘쳐 못 보낸 결과와 최종 판정은 DB에서 읽는다
+					if err := db.First(&submission, uint(submissionID)).Error; err != nil {
+						return
+					}
+					sendResults(submission.Results)
+					sendSummary()
+					return
+				}
+				sendResults([]judge.TestResult{r})
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}
+}
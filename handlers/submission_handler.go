@@ -0,0 +1,532 @@
+// handlers/submission_handler.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"Flow-Chart-Block-Coding-Backend/flowchart"
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"Flow-Chart-Block-Coding-Backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetUserSubmissions는 사용자가 특정 문제에 제출한 기록을 최신순으로 반환합니다.
+func GetUserSubmissions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userName := c.Param("username")
+		problemID, err := strconv.ParseUint(c.Param("problem_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 문제 ID 형식입니다",
+			})
+			return
+		}
+
+		var submissions []models.Submission
+		err = db.Where("user_name = ? AND problem_id = ?", userName, uint(problemID)).
+			Order("submitted_at DESC, id DESC").Find(&submissions).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "제출 기록을 조회하는데 실패했습니다",
+			})
+			return
+		}
+
+		// 목록에는 코드와 테스트케이스별 결과 대신 요약만 담는다
+		items := make([]gin.H, 0, len(submissions))
+		for _, s := range submissions {
+			passed := 0
+			for _, r := range s.Results {
+				if r.Passed {
+					passed++
+				}
+			}
+			items = append(items, gin.H{
+				"submissionId": s.ID,
+				"status":       s.Status,
+				"verdict":      s.Verdict,
+				"score":        s.Score,
+				"maxScore":     s.MaxScore,
+				"passed":       passed,
+				"total":        s.CaseCount,
+				"submittedAt":  s.SubmittedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"userName":    userName,
+				"problemId":   uint(problemID),
+				"submissions": items,
+			},
+		})
+	}
+}
+
+// GetSubmission은 제출 하나의 코드와 테스트케이스별 결과를 반환합니다. 코드와
+// 순서도는 제출한 학생의 클래스나 문제를 낸 클래스로 인증된 요청에만 보여줍니다.
+func GetSubmission(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submissionID, err := strconv.ParseUint(c.Param("submission_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 제출 ID 형식입니다",
+			})
+			return
+		}
+
+		var submission models.Submission
+		if err := db.First(&submission, uint(submissionID)).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 제출입니다",
+			})
+			return
+		}
+
+		var problem models.Problem
+		if err := db.Preload("Testcases", orderTestcases).First(&problem, submission.ProblemID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 문제입니다",
+			})
+			return
+		}
+		visible := resultVisibility(c, &problem)
+		results := make([]judge.TestResult, len(submission.Results))
+		for i, r := range submission.Results {
+			results[i] = visible(r)
+		}
+
+		data := gin.H{
+			"submissionId": submission.ID,
+			"problemId":    submission.ProblemID,
+			"userName":     submission.UserName,
+			"engine":       submission.Engine,
+			"seed":         submission.Seed,
+			"status":       submission.Status,
+			"progress": gin.H{
+				"completed": len(submission.Results),
+				"total":     submission.CaseCount,
+			},
+			"verdict":     submission.Verdict,
+			"score":       submission.Score,
+			"maxScore":    submission.MaxScore,
+			"results":     results,
+			"submittedAt": submission.SubmittedAt,
+		}
+		if canViewCode(c, db, &submission, &problem) {
+			data["code"] = submission.Code
+			data["flowchart"] = submission.Flowchart
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    data,
+		})
+	}
+}
+
+// canViewCode는 요청이 제출한 학생의 클래스나 문제를 낸 클래스로 인증되었는지
+// 알려줍니다. 다른 학생의 코드를 ID만으로 읽지 못하게 합니다.
+func canViewCode(c *gin.Context, db *gorm.DB, submission *models.Submission, problem *models.Problem) bool {
+	if isClassOwner(c, problem.ClassID) {
+		return true
+	}
+	classnum, exists := c.Get("classnum")
+	if !exists {
+		return false
+	}
+	var user models.User
+	if err := db.First(&user, submission.UserID).Error; err != nil {
+		return false
+	}
+	return user.Classnum == classnum.(string)
+}
+
+// submitAsync는 제출을 queued 상태로 기록하고 백그라운드에서 채점을 시작한 뒤
+// 채점을 기다리지 않고 제출 ID를 응답합니다. 진행 상황은 GetSubmission으로 확인합니다.
+// 문제에 생성기가 있으면 무작위 테스트케이스도 백그라운드에서 만듭니다.
+func submitAsync(c *gin.Context, db *gorm.DB, judgeService *judge.Judge, exec judge.Executor, record models.Submission,
+	user *models.User, problem *models.Problem, cases []judge.TestCase, limits judge.Limits, check judge.Checker) {
+	// 응답을 보낸 뒤에도 채점이 이어져야 하므로 요청 컨텍스트를 쓰지 않는다
+	ctx, cancel := context.WithCancel(context.Background())
+	// 무작위 테스트케이스를 만들 필요가 없으면 대기열에 먼저 넣어, 가득 찼을 때
+	// 제출 기록을 남기지 않고 바로 알린다
+	var job *judge.Job
+	if problem.Generator == nil {
+		var err error
+		job, err = judgeService.Submit(ctx, exec, cases, limits, check)
+		if errors.Is(err, judge.ErrQueueFull) {
+			cancel()
+			respondQueueFull(c)
+			return
+		}
+		if err != nil {
+			cancel()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "채점에 실패했습니다",
+			})
+			return
+		}
+	}
+
+	record.Status = models.SubmissionQueued
+	record.Results = []judge.TestResult{}
+	if err := db.Create(&record).Error; err != nil {
+		cancel()
+		if job != nil {
+			go func() {
+				for range job.Results {
+				}
+			}()
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "제출 기록 저장에 실패했습니다",
+		})
+		return
+	}
+
+	go func() {
+		defer cancel()
+		if job == nil {
+			var err error
+			cases, job, err = submitGenerated(ctx, db, judgeService, exec, &record, problem, cases, limits, check)
+			if err != nil {
+				log.Printf("submission %d: failed to start grading: %v", record.ID, err)
+				failSubmission(db, record.ID, err)
+				return
+			}
+		}
+		trackSubmission(db, record, *user, cases, job)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":      true,
+		"message":      "채점 대기 중입니다",
+		"status":       record.Status,
+		"submissionId": record.ID,
+	})
+}
+
+// submitGenerated는 제출의 시드로 무작위 테스트케이스를 만들어 cases 뒤에 붙이고,
+// 늘어난 테스트케이스 수와 만점을 기록한 뒤 채점을 대기열에 넣습니다.
+func submitGenerated(ctx context.Context, db *gorm.DB, judgeService *judge.Judge, exec judge.Executor, record *models.Submission,
+	problem *models.Problem, cases []judge.TestCase, limits judge.Limits, check judge.Checker) ([]judge.TestCase, *judge.Job, error) {
+	generated, err := generatedCases(ctx, judgeService, problem, record.Seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	cases = append(cases, generated...)
+	record.CaseCount = len(cases)
+	record.MaxScore = submissionMaxScore(problem, cases)
+	err = db.Model(&models.Submission{ID: record.ID}).Select("CaseCount", "MaxScore").Updates(&models.Submission{
+		CaseCount: record.CaseCount,
+		MaxScore:  record.MaxScore,
+	}).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := judgeService.Submit(ctx, exec, cases, limits, check)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cases, job, nil
+}
+
+// failSubmission은 채점을 시작하지 못한 제출을 끝냅니다. 대기열이 가득 찼으면
+// 다시 제출할 수 있도록 canceled로, 그 밖의 이유(참조 풀이 실패 등)면 JudgeError로
+// 끝냅니다. 학생의 잘못이 아니므로 점수는 기록하지 않습니다.
+func failSubmission(db *gorm.DB, submissionID uint, cause error) {
+	update := models.Submission{Status: models.SubmissionFinished, Verdict: judge.JudgeError}
+	if errors.Is(cause, judge.ErrQueueFull) {
+		update = models.Submission{Status: models.SubmissionCanceled}
+	}
+	err := db.Model(&models.Submission{ID: submissionID}).Select("Status", "Verdict").Updates(&update).Error
+	submissionEvents.finish(submissionID)
+	if err != nil {
+		log.Printf("submission %d: failed to save result: %v", submissionID, err)
+	}
+}
+
+// trackSubmission은 채점이 끝날 때까지 결과를 받아 제출 기록의 상태와 결과를 갱신합니다.
+// 점수가 사용자의 최고 점수보다 높으면 갱신하고, 모두 통과하면 solved 테이블에도 추가합니다.
+func trackSubmission(db *gorm.DB, submission models.Submission, user models.User, cases []judge.TestCase, job *judge.Job) {
+	submissionID := submission.ID
+	// gorm 체인은 재사용하면 조건이 누적되므로 갱신할 때마다 새로 만든다
+	record := func() *gorm.DB { return db.Model(&models.Submission{ID: submissionID}) }
+	results := make([]judge.TestResult, 0, len(cases))
+
+	started := job.Started
+	for done := false; !done; {
+		select {
+		case <-started:
+			started = nil
+			if err := record().Update("status", models.SubmissionRunning).Error; err != nil {
+				log.Printf("submission %d: failed to update status: %v", submissionID, err)
+			}
+		case r, ok := <-job.Results:
+			if !ok {
+				done = true
+				break
+			}
+			results = append(results, r)
+			err := record().Select("Status", "Results").Updates(&models.Submission{
+				Status:  models.SubmissionRunning,
+				Results: results,
+			}).Error
+			if err != nil {
+				log.Printf("submission %d: failed to save progress: %v", submissionID, err)
+			}
+			submissionEvents.publish(submissionID, r)
+		}
+	}
+
+	// 끝난 순서로 모인 결과를 테스트케이스 순서로 정렬
+	positions := make(map[int]int, len(cases))
+	for i, tc := range cases {
+		positions[tc.ID] = i
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return positions[results[a].TestCaseID] < positions[results[b].TestCaseID]
+	})
+
+	verdict := judge.Overall(results)
+	submission.Score = judge.Score(cases, results, submission.MaxScore)
+	err := record().Select("Status", "Verdict", "Score", "Results").Updates(&models.Submission{
+		Status:  models.SubmissionFinished,
+		Verdict: verdict,
+		Score:   submission.Score,
+		Results: results,
+	}).Error
+	// 구독자는 채널이 닫히면 DB에서 최종 판정을 읽으므로 저장한 뒤에 알린다
+	submissionEvents.finish(submissionID)
+	if err != nil {
+		log.Printf("submission %d: failed to save result: %v", submissionID, err)
+		return
+	}
+
+	if err := recordBestScore(db, &user, &submission); err != nil {
+		log.Printf("submission %d: failed to record best score: %v", submissionID, err)
+	}
+	if verdict == judge.Accepted {
+		if _, err := recordSolved(db, &user, submission.ProblemID); err != nil {
+			log.Printf("submission %d: failed to record solved: %v", submissionID, err)
+		}
+	}
+}
+
+// resultVisibility는 문제의 테스트케이스 공개 여부에 따라 요청한 사람에게 보여줄
+// 결과로 바꿔주는 함수를 만듭니다. 채점 이후 삭제된 테스트케이스와 무작위
+// 테스트케이스는 비공개로 취급합니다. problem은 Testcases를 채점 순서로 불러온
+// 것이어야 합니다.
+func resultVisibility(c *gin.Context, problem *models.Problem) func(judge.TestResult) judge.TestResult {
+	samples := make(map[int]bool, len(problem.Testcases))
+	positions := make(map[int]int, len(problem.Testcases))
+	for i, tc := range problem.Testcases {
+		samples[int(tc.ID)] = tc.IsSample
+		positions[int(tc.ID)] = i + 1
+	}
+	return func(r judge.TestResult) judge.TestResult {
+		n := positions[r.TestCaseID]
+		if r.TestCaseID < 0 { // 무작위 테스트케이스는 저장된 케이스 뒤에 채점한다
+			n = len(problem.Testcases) - r.TestCaseID
+		}
+		return visibleResult(c, problem, samples[r.TestCaseID], n, r)
+	}
+}
+
+// visibleResult는 비공개 테스트케이스 결과에서 입력과 정답을 짐작할 수 있는
+// 메시지, 에러, 실제 출력을 지웁니다. 문제를 낸 클래스에게는 그대로 보여줍니다.
+func visibleResult(c *gin.Context, problem *models.Problem, sample bool, n int, result judge.TestResult) judge.TestResult {
+	if sample || isClassOwner(c, problem.ClassID) {
+		return result
+	}
+
+	result.Error = nil
+	result.Output = nil
+	if result.Passed {
+		result.Message = "테스트 통과"
+	} else {
+		result.Message = fmt.Sprintf("비공개 테스트케이스 #%d 실패", n)
+	}
+	return result
+}
+
+// resultMessage는 결과를 사용자에게 보여줄 한 줄 메시지로 바꿉니다.
+func resultMessage(result judge.TestResult) string {
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	return result.Message
+}
+
+// 실행 기록에 남길 수 있는 사건 수
+const (
+	defaultTraceEvents = 1000
+	maxTraceEvents     = 10000
+)
+
+// GetSubmissionTrace는 제출을 테스트케이스 하나로 다시 실행하면서 방문한 블록,
+// 블록마다의 변수 값, 입출력을 기록해 반환합니다. 화면에서 실행 과정을 한 단계씩
+// 보여줄 때 씁니다. 제출한 학생의 클래스나 문제를 낸 클래스만 부를 수 있고,
+// 비공개 테스트케이스는 문제를 낸 클래스만 볼 수 있습니다.
+// 음수 case_id는 제출의 시드로 다시 만든 무작위 테스트케이스입니다.
+func GetSubmissionTrace(db *gorm.DB, judgeService *judge.Judge) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submissionID, err := strconv.ParseUint(c.Param("submission_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 제출 ID 형식입니다",
+			})
+			return
+		}
+		caseID, err := strconv.ParseInt(c.Param("case_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "잘못된 테스트케이스 ID 형식입니다",
+			})
+			return
+		}
+		maxEvents := defaultTraceEvents
+		if limit := c.Query("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"message": "잘못된 limit 값입니다",
+				})
+				return
+			}
+			maxEvents = min(n, maxTraceEvents)
+		}
+
+		var submission models.Submission
+		if err := db.First(&submission, uint(submissionID)).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 제출입니다",
+			})
+			return
+		}
+		var problem models.Problem
+		if err := db.First(&problem, submission.ProblemID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 문제입니다",
+			})
+			return
+		}
+		if !canViewCode(c, db, &submission, &problem) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": "다른 학생의 제출은 볼 수 없습니다",
+			})
+			return
+		}
+		var testcase models.Testcase
+		if caseID > 0 {
+			if err := db.Where("id = ? AND problem_id = ?", uint(caseID), problem.ID).First(&testcase).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{
+					"success": false,
+					"message": "존재하지 않는 테스트케이스입니다",
+				})
+				return
+			}
+		} else if caseID == 0 || submission.Seed == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "존재하지 않는 테스트케이스입니다",
+			})
+			return
+		}
+		if !testcase.IsSample && !isClassOwner(c, problem.ClassID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": "비공개 테스트케이스의 실행 기록은 볼 수 없습니다",
+			})
+			return
+		}
+
+		check, err := judge.NewChecker(problem.Checker)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "문제의 채점 방식 설정이 잘못되었습니다",
+			})
+			return
+		}
+
+		tc := judgeCase(&problem, &testcase)
+		if caseID < 0 {
+			// 무작위 테스트케이스는 제출에 기록된 시드로 다시 만든다
+			generated, err := generatedCases(c.Request.Context(), judgeService, &problem, submission.Seed)
+			if errors.Is(err, judge.ErrQueueFull) {
+				respondQueueFull(c)
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": "무작위 테스트케이스를 만들지 못했습니다",
+				})
+				return
+			}
+			index := int(-caseID) - 1
+			if index >= len(generated) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"success": false,
+					"message": "존재하지 않는 테스트케이스입니다",
+				})
+				return
+			}
+			tc = generated[index]
+		}
+		// 순서도로 낸 제출은 채점에 쓴 실행 방법과 상관없이 블록 단위로 기록되도록
+		// 인터프리터로 실행한다
+		exec, engine := submissionExecutor(&submission), submission.Engine
+		if submission.Flowchart != nil {
+			exec, engine = flowchart.NewInterpreter(submission.Flowchart), models.EngineInterpreter
+		}
+		trace, err := judgeService.Trace(c.Request.Context(), exec, tc, problemLimits(&problem), check, maxEvents)
+		if errors.Is(err, judge.ErrQueueFull) {
+			respondQueueFull(c)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "실행 기록을 만들지 못했습니다",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"submissionId": submission.ID,
+				"testCaseId":   tc.ID,
+				"engine":       engine,
+				"events":       trace.Events,
+				"truncated":    trace.Truncated,
+				"result":       trace.Result,
+			},
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"Flow-Chart-Block-Coding-Backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TestcaseHandler handles operations on Testcase model
+type TestcaseHandler struct {
+	DB    *gorm.DB
+	Judge *judge.Judge // runs reference solutions
+}
+
+func NewTestcaseHandler(db *gorm.DB, judgeService *judge.Judge) *TestcaseHandler {
+	return &TestcaseHandler{DB: db, Judge: judgeService}
+}
+
+// ownedProblem loads the problem in the :id path parameter and verifies that
+// it belongs to the authenticated class. It writes the error response itself.
+func (h *TestcaseHandler) ownedProblem(c *gin.Context) (*models.Problem, bool) {
+	classID, exists := c.Get("class_id")
+	if !exists {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authenticated"})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return nil, false
+	}
+
+	var problem models.Problem
+	if err := h.DB.First(&problem, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return nil, false
+	}
+
+	if classID.(uint) != problem.ClassID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this problem"})
+		return nil, false
+	}
+
+	return &problem, true
+}
+
+// findTestcase loads the testcase in the :testcase_id path parameter that
+// belongs to the given problem. It writes the error response itself.
+func (h *TestcaseHandler) findTestcase(c *gin.Context, problem *models.Problem) (*models.Testcase, bool) {
+	id, err := strconv.ParseUint(c.Param("testcase_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid testcase ID format"})
+		return nil, false
+	}
+
+	var testcase models.Testcase
+	err = h.DB.Where("problem_id = ?", problem.ID).First(&testcase, uint(id)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Testcase not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch testcase"})
+		return nil, false
+	}
+
+	return &testcase, true
+}
+
+func (h *TestcaseHandler) ListTestcases(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+
+	var testcases []models.Testcase
+	if err := h.DB.Scopes(orderTestcases).Where("problem_id = ?", problem.ID).Find(&testcases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list testcases"})
+		return
+	}
+	c.JSON(http.StatusOK, testcases)
+}
+
+func (h *TestcaseHandler) GetTestcase(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+	testcase, ok := h.findTestcase(c, problem)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, testcase)
+}
+
+func (h *TestcaseHandler) CreateTestcase(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+
+	var testcase models.Testcase
+	if err := c.ShouldBindJSON(&testcase); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if testcase.Points < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Points must not be negative"})
+		return
+	}
+	testcase.ID = 0
+	testcase.ProblemID = problem.ID
+	if !h.fillExpectedOutput(c, problem, &testcase) {
+		return
+	}
+
+	if err := h.DB.Create(&testcase).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create testcase"})
+		return
+	}
+	c.JSON(http.StatusCreated, testcase)
+}
+
+func (h *TestcaseHandler) UpdateTestcase(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+	testcase, ok := h.findTestcase(c, problem)
+	if !ok {
+		return
+	}
+
+	id := testcase.ID
+	if err := c.ShouldBindJSON(testcase); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if testcase.Points < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Points must not be negative"})
+		return
+	}
+	testcase.ID = id
+	testcase.ProblemID = problem.ID
+	if !h.fillExpectedOutput(c, problem, testcase) {
+		return
+	}
+
+	if err := h.DB.Save(testcase).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update testcase"})
+		return
+	}
+	c.JSON(http.StatusOK, testcase)
+}
+
+func (h *TestcaseHandler) DeleteTestcase(c *gin.Context) {
+	problem, ok := h.ownedProblem(c)
+	if !ok {
+		return
+	}
+	testcase, ok := h.findTestcase(c, problem)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(testcase).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete testcase"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Testcase deleted successfully"})
+}
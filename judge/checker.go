@@ -0,0 +1,286 @@
+// judge/checker.go
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Checker는 프로그램이 끝난 뒤 출력이 맞는지 판정합니다. 여러 워커에서 동시에 불립니다.
+type Checker interface {
+	Check(tc TestCase, actual []string) (Verdict, string)
+}
+
+// 채점 방식
+const (
+	CheckExact      = "exact"      // 줄마다 앞뒤 공백만 무시하고 비교 (기본값)
+	CheckWhitespace = "whitespace" // 공백과 줄바꿈을 모두 무시하고 단어 단위로 비교
+	CheckCase       = "case"       // exact와 같지만 대소문자를 구분하지 않음
+	CheckNumeric    = "numeric"    // 단어 단위로, 숫자는 허용 오차 안이면 같다고 봄
+	CheckUnordered  = "unordered"  // 줄의 순서를 무시하고 비교
+	CheckScript     = "script"     // 선생님이 작성한 JavaScript check 함수로 판정
+)
+
+// CheckerSpec은 문제에 저장하는 채점 방식입니다.
+type CheckerSpec struct {
+	Mode         string  `json:"mode"`
+	AbsTolerance float64 `json:"absTolerance,omitempty"` // numeric: 절대 오차
+	RelTolerance float64 `json:"relTolerance,omitempty"` // numeric: 정답 대비 상대 오차
+	Script       string  `json:"script,omitempty"`       // script: check(input, expected, actual) 함수를 정의하는 코드
+}
+
+// Exact는 기본 채점 방식입니다
+var Exact Checker = exactChecker{}
+
+// NewChecker는 채점 방식에 맞는 Checker를 만듭니다. spec이 nil이거나 Mode가
+// 비어 있으면 Exact입니다. check 함수 코드에 문법 오류가 있으면 에러를 반환합니다.
+func NewChecker(spec *CheckerSpec) (Checker, error) {
+	if spec == nil {
+		return Exact, nil
+	}
+	switch spec.Mode {
+	case "", CheckExact:
+		return Exact, nil
+	case CheckWhitespace:
+		return whitespaceChecker{}, nil
+	case CheckCase:
+		return caseChecker{}, nil
+	case CheckNumeric:
+		if spec.AbsTolerance < 0 || spec.RelTolerance < 0 {
+			return nil, errors.New("허용 오차는 0 이상이어야 합니다")
+		}
+		return numericChecker{abs: spec.AbsTolerance, rel: spec.RelTolerance}, nil
+	case CheckUnordered:
+		return unorderedChecker{}, nil
+	case CheckScript:
+		return newScriptChecker(spec.Script)
+	}
+	return nil, fmt.Errorf("알 수 없는 채점 방식 %q", spec.Mode)
+}
+
+type exactChecker struct{}
+
+// Check는 줄 단위로 앞뒤 공백을 무시하고 출력을 비교합니다.
+// 공백과 줄바꿈만 다른 경우는 오답 대신 PresentationError로 구분합니다.
+func (exactChecker) Check(tc TestCase, actual []string) (Verdict, string) {
+	return compareLines(tc.Output, actual, func(a, b string) bool { return a == b })
+}
+
+type caseChecker struct{}
+
+func (caseChecker) Check(tc TestCase, actual []string) (Verdict, string) {
+	return compareLines(tc.Output, actual, strings.EqualFold)
+}
+
+// compareLines는 줄마다 앞뒤 공백을 지운 뒤 equal로 비교합니다
+func compareLines(expected, actual []string, equal func(a, b string) bool) (Verdict, string) {
+	matched := len(expected) == len(actual)
+	for i := 0; matched && i < len(expected); i++ {
+		matched = equal(strings.TrimSpace(expected[i]), strings.TrimSpace(actual[i]))
+	}
+	if matched {
+		return Accepted, "테스트 통과"
+	}
+
+	wantWords, gotWords := words(expected), words(actual)
+	if len(wantWords) == len(gotWords) {
+		sameWords := true
+		for i := range wantWords {
+			sameWords = sameWords && equal(wantWords[i], gotWords[i])
+		}
+		if sameWords {
+			return PresentationError, "출력 형식 불일치 (공백 또는 줄바꿈이 다릅니다)"
+		}
+	}
+
+	if len(expected) != len(actual) {
+		return WrongAnswer, fmt.Sprintf("출력 개수 불일치\n예상: %d개\n실제: %d개",
+			len(expected), len(actual))
+	}
+
+	for i, expectedOutput := range expected {
+		want := strings.TrimSpace(expectedOutput)
+		got := strings.TrimSpace(actual[i])
+		if !equal(want, got) {
+			return WrongAnswer, fmt.Sprintf("출력 불일치 (출력 #%d)\n예상: %s\n실제: %s",
+				i+1, want, got)
+		}
+	}
+	return WrongAnswer, "출력 불일치"
+}
+
+// words는 출력 전체를 공백과 줄바꿈으로 나눈 단어들입니다
+func words(lines []string) []string {
+	return strings.Fields(strings.Join(lines, "\n"))
+}
+
+type whitespaceChecker struct{}
+
+func (whitespaceChecker) Check(tc TestCase, actual []string) (Verdict, string) {
+	want, got := words(tc.Output), words(actual)
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if want[i] != got[i] {
+			return WrongAnswer, fmt.Sprintf("출력 불일치 (단어 #%d)\n예상: %s\n실제: %s", i+1, want[i], got[i])
+		}
+	}
+	if len(want) != len(got) {
+		return WrongAnswer, fmt.Sprintf("출력 개수 불일치\n예상: 단어 %d개\n실제: 단어 %d개", len(want), len(got))
+	}
+	return Accepted, "테스트 통과"
+}
+
+type numericChecker struct {
+	abs, rel float64
+}
+
+// Check는 단어 단위로 비교하되, 둘 다 숫자인 단어는 절대 오차나 상대 오차
+// 중 하나라도 안에 들면 같다고 봅니다.
+func (n numericChecker) Check(tc TestCase, actual []string) (Verdict, string) {
+	want, got := words(tc.Output), words(actual)
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if !n.equal(want[i], got[i]) {
+			return WrongAnswer, fmt.Sprintf("출력 불일치 (단어 #%d)\n예상: %s\n실제: %s", i+1, want[i], got[i])
+		}
+	}
+	if len(want) != len(got) {
+		return WrongAnswer, fmt.Sprintf("출력 개수 불일치\n예상: 단어 %d개\n실제: 단어 %d개", len(want), len(got))
+	}
+	return Accepted, "테스트 통과"
+}
+
+func (n numericChecker) equal(want, got string) bool {
+	x, errX := strconv.ParseFloat(want, 64)
+	y, errY := strconv.ParseFloat(got, 64)
+	if errX != nil || errY != nil || math.IsNaN(x) || math.IsNaN(y) {
+		return want == got
+	}
+	diff := math.Abs(x - y)
+	return x == y || diff <= n.abs || diff <= n.rel*math.Abs(x)
+}
+
+type unorderedChecker struct{}
+
+// Check는 빈 줄을 빼고 남은 줄들을 정렬해서 비교합니다
+func (unorderedChecker) Check(tc TestCase, actual []string) (Verdict, string) {
+	want, got := sortedLines(tc.Output), sortedLines(actual)
+	if len(want) != len(got) {
+		return WrongAnswer, fmt.Sprintf("출력 개수 불일치\n예상: %d개\n실제: %d개", len(want), len(got))
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			return WrongAnswer, "출력 불일치 (순서와 상관없이 같은 줄들을 출력해야 합니다)"
+		}
+	}
+	return Accepted, "테스트 통과"
+}
+
+func sortedLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkerTimeout은 check 함수 한 번에 쓸 수 있는 시간입니다
+const checkerTimeout = time.Second
+
+var errCheckerTimeout = errors.New("check 함수 시간 초과")
+
+// scriptChecker는 선생님이 작성한 check 함수로 판정합니다
+type scriptChecker struct {
+	program *goja.Program
+}
+
+// newScriptChecker는 check 함수 코드를 컴파일하고, 실제로 check 함수를 정의하는지 확인합니다.
+func newScriptChecker(code string) (Checker, error) {
+	program, err := compileSandboxed("checker", code)
+	if err != nil {
+		return nil, fmt.Errorf("check 함수 문법 오류: %v", err)
+	}
+	c := scriptChecker{program: program}
+	vm := newSandbox()
+	timer := time.AfterFunc(checkerTimeout, func() { vm.Interrupt(errCheckerTimeout) })
+	defer timer.Stop()
+	if _, err := c.load(vm); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load는 vm에서 코드를 실행하고 check 함수를 꺼냅니다
+func (c scriptChecker) load(vm *goja.Runtime) (goja.Callable, error) {
+	if _, err := vm.RunProgram(c.program); err != nil {
+		return nil, fmt.Errorf("check 함수 코드 실행 오류: %v", err)
+	}
+	check, ok := goja.AssertFunction(vm.Get("check"))
+	if !ok {
+		return nil, errors.New("check(input, expected, actual) 함수가 정의되어 있지 않습니다")
+	}
+	return check, nil
+}
+
+// Check는 학생 코드와 분리된 새 샌드박스 VM에서 check(input, expected, actual)를 부릅니다.
+// 세 인자는 모두 문자열 배열이고, check는 true/false나 오답일 때 보여줄 메시지
+// 문자열, 또는 {correct, message} 객체를 돌려줍니다. check 함수가 실패하면
+// 학생의 잘못이 아니므로 JudgeError로 판정합니다.
+func (c scriptChecker) Check(tc TestCase, actual []string) (verdict Verdict, message string) {
+	vm := newSandbox()
+	timer := time.AfterFunc(checkerTimeout, func() { vm.Interrupt(errCheckerTimeout) })
+	defer timer.Stop()
+	defer func() {
+		if r := recover(); r != nil {
+			verdict, message = JudgeError, fmt.Sprintf("check 함수 오류: %v", r)
+		}
+	}()
+
+	check, err := c.load(vm)
+	if err != nil {
+		return JudgeError, err.Error()
+	}
+	ret, err := check(goja.Undefined(), vm.ToValue(stringsOrEmpty(tc.Input)), vm.ToValue(stringsOrEmpty(tc.Output)), vm.ToValue(stringsOrEmpty(actual)))
+	if err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			return JudgeError, errCheckerTimeout.Error()
+		}
+		return JudgeError, fmt.Sprintf("check 함수 오류: %v", err)
+	}
+
+	correct, message := false, "출력 불일치"
+	switch v := ret.Export().(type) {
+	case bool:
+		correct = v
+	case string:
+		message = v
+	case map[string]interface{}:
+		correct, _ = v["correct"].(bool)
+		if m, ok := v["message"].(string); ok && m != "" {
+			message = m
+		}
+	default:
+		return JudgeError, "check 함수는 true/false, 메시지 문자열, {correct, message} 중 하나를 돌려줘야 합니다"
+	}
+	if correct {
+		return Accepted, "테스트 통과"
+	}
+	return WrongAnswer, message
+}
+
+// stringsOrEmpty는 nil 대신 빈 배열을 넘겨 check 함수가 length를 바로 쓸 수 있게 합니다
+func stringsOrEmpty(lines []string) []string {
+	if lines == nil {
+		return []string{}
+	}
+	return lines
+}
@@ -0,0 +1,70 @@
+package judge
+
+import "testing"
+
+func TestCheckers(t *testing.T) {
+	divisor := `function check(input, expected, actual) {
+		var n = Number(input[0]), d = Number(actual[0]);
+		if (actual.length !== 1 || !(d > 1 && d < n && n % d === 0)) {
+			return n + "의 약수가 아닙니다";
+		}
+		return true;
+	}`
+
+	tests := []struct {
+		name   string
+		spec   *CheckerSpec
+		input  []string
+		want   []string
+		actual []string
+		ok     Verdict
+	}{
+		{"exact", nil, nil, []string{"Hello"}, []string{"Hello  "}, Accepted},
+		{"exact wrong", nil, nil, []string{"Hello"}, []string{"hello"}, WrongAnswer},
+		{"exact presentation", &CheckerSpec{Mode: CheckExact}, nil, []string{"1 2"}, []string{"1", "2"}, PresentationError},
+		{"case", &CheckerSpec{Mode: CheckCase}, nil, []string{"YES"}, []string{"yes"}, Accepted},
+		{"whitespace", &CheckerSpec{Mode: CheckWhitespace}, nil, []string{"1 2 3"}, []string{"1", "2  3"}, Accepted},
+		{"whitespace wrong", &CheckerSpec{Mode: CheckWhitespace}, nil, []string{"1 2 3"}, []string{"1 2"}, WrongAnswer},
+		{"numeric abs", &CheckerSpec{Mode: CheckNumeric, AbsTolerance: 1e-6}, nil, []string{"0.333333"}, []string{"0.3333333333"}, Accepted},
+		{"numeric rel", &CheckerSpec{Mode: CheckNumeric, RelTolerance: 1e-3}, nil, []string{"1000000"}, []string{"1000500"}, Accepted},
+		{"numeric wrong", &CheckerSpec{Mode: CheckNumeric, AbsTolerance: 1e-6}, nil, []string{"0.5"}, []string{"0.51"}, WrongAnswer},
+		{"numeric words", &CheckerSpec{Mode: CheckNumeric, AbsTolerance: 0.1}, nil, []string{"area 3.14"}, []string{"area 3.1"}, Accepted},
+		{"unordered", &CheckerSpec{Mode: CheckUnordered}, nil, []string{"a", "b", "c"}, []string{"c", "a", "b", ""}, Accepted},
+		{"unordered wrong", &CheckerSpec{Mode: CheckUnordered}, nil, []string{"a", "b"}, []string{"a", "a"}, WrongAnswer},
+		{"script accepts any divisor", &CheckerSpec{Mode: CheckScript, Script: divisor}, []string{"12"}, []string{"2"}, []string{"4"}, Accepted},
+		{"script rejects", &CheckerSpec{Mode: CheckScript, Script: divisor}, []string{"12"}, []string{"2"}, []string{"5"}, WrongAnswer},
+		{"script object", &CheckerSpec{Mode: CheckScript, Script: `function check(i, e, a) { return {correct: a[0] === "ok"}; }`}, nil, nil, []string{"ok"}, Accepted},
+		{"script throws", &CheckerSpec{Mode: CheckScript, Script: `function check() { throw new Error("oops"); }`}, nil, nil, nil, JudgeError},
+		{"script timeout", &CheckerSpec{Mode: CheckScript, Script: `function check() { for (;;) {} }`}, nil, nil, nil, JudgeError},
+		{"script bad return", &CheckerSpec{Mode: CheckScript, Script: `function check() { return 1; }`}, nil, nil, nil, JudgeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := NewChecker(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verdict, message := check.Check(TestCase{ID: 1, Input: tt.input, Output: tt.want}, tt.actual)
+			if verdict != tt.ok {
+				t.Errorf("verdict = %s (%s), want %s", verdict, message, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewCheckerRejectsInvalidSpecs(t *testing.T) {
+	invalid := []*CheckerSpec{
+		{Mode: "fuzzy"},
+		{Mode: CheckNumeric, AbsTolerance: -1},
+		{Mode: CheckScript, Script: "function check( {"},
+		{Mode: CheckScript, Script: "var x = 1;"},
+		{Mode: CheckScript, Script: "for (;;) {} function check() { return true; }"},
+		{Mode: CheckScript, Script: "var f = Function('return 1'); function check() { return true; }"},
+		{Mode: CheckScript, Script: "function check() { return 10n > 1n; }"},
+	}
+	for _, spec := range invalid {
+		if _, err := NewChecker(spec); err == nil {
+			t.Errorf("%+v: expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// judge/constraints.go
+package judge
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/token"
+)
+
+// 구조 제한에 쓰는 블록 종류. 판단 블록은 반복(loop)과 조건(if)으로도 나눠 셉니다.
+const (
+	BlockInput    = "input"
+	BlockOutput   = "output"
+	BlockProcess  = "process"
+	BlockDecision = "decision"
+	BlockLoop     = "loop"
+	BlockIf       = "if"
+)
+
+var blockTypes = map[string]bool{
+	BlockInput: true, BlockOutput: true, BlockProcess: true,
+	BlockDecision: true, BlockLoop: true, BlockIf: true,
+}
+
+// Constraints는 문제가 풀이의 모양에 거는 제한입니다. 예를 들어 "반복문으로 1부터
+// N까지 더하기" 문제는 Required에 loop를 넣어 공식으로 푼 풀이를 막습니다.
+type Constraints struct {
+	Required  []string `json:"required,omitempty"`  // 반드시 써야 하는 블록 종류
+	Forbidden []string `json:"forbidden,omitempty"` // 쓸 수 없는 블록 종류
+	MaxNodes  int      `json:"maxNodes,omitempty"`  // 블록 수 상한 (시작/끝 제외, 0이면 제한 없음)
+	MaxDepth  int      `json:"maxDepth,omitempty"`  // 반복/조건 중첩 깊이 상한 (0이면 제한 없음)
+}
+
+// Empty는 확인할 제한이 없는지 알려줍니다
+func (c *Constraints) Empty() bool {
+	return c == nil || (len(c.Required) == 0 && len(c.Forbidden) == 0 && c.MaxNodes == 0 && c.MaxDepth == 0)
+}
+
+// Validate는 문제를 저장하기 전에 제한 값이 올바른지 확인합니다
+func (c *Constraints) Validate() error {
+	for _, list := range [][]string{c.Required, c.Forbidden} {
+		for _, b := range list {
+			if !blockTypes[b] {
+				return fmt.Errorf("알 수 없는 블록 종류 %q", b)
+			}
+		}
+	}
+	for _, b := range c.Required {
+		for _, f := range c.Forbidden {
+			if b == f {
+				return fmt.Errorf("블록 종류 %q가 필수이면서 금지되어 있습니다", b)
+			}
+		}
+	}
+	if c.MaxNodes < 0 || c.MaxDepth < 0 {
+		return fmt.Errorf("블록 수와 중첩 깊이 제한은 0 이상이어야 합니다")
+	}
+	return nil
+}
+
+// Structure는 실행하지 않고 읽어낸 풀이의 모양입니다.
+type Structure struct {
+	Blocks map[string]int // 종류별 블록 수
+	Depth  int            // 반복/조건 중첩 깊이, 구할 수 없으면 -1
+}
+
+// Nodes는 시작/끝을 뺀 블록 수입니다 (판단 블록은 loop/if로 다시 세지 않음)
+func (s *Structure) Nodes() int {
+	return s.Blocks[BlockInput] + s.Blocks[BlockOutput] + s.Blocks[BlockProcess] + s.Blocks[BlockDecision]
+}
+
+// Check는 풀이가 제한을 어기는 내용을 모두 돌려줍니다
+func (c *Constraints) Check(s *Structure) []string {
+	var problems []string
+	for _, b := range c.Required {
+		if s.Blocks[b] == 0 {
+			problems = append(problems, fmt.Sprintf("%s 블록을 사용해야 합니다", b))
+		}
+	}
+	for _, b := range c.Forbidden {
+		if s.Blocks[b] > 0 {
+			problems = append(problems, fmt.Sprintf("%s 블록은 사용할 수 없습니다", b))
+		}
+	}
+	if c.MaxNodes > 0 && s.Nodes() > c.MaxNodes {
+		problems = append(problems, fmt.Sprintf("블록이 너무 많습니다 (%d개, 최대 %d개)", s.Nodes(), c.MaxNodes))
+	}
+	if c.MaxDepth > 0 {
+		switch {
+		case s.Depth < 0:
+			problems = append(problems, "반복과 조건으로 나타낼 수 없는 순서도라 중첩 깊이를 확인할 수 없습니다")
+		case s.Depth > c.MaxDepth:
+			problems = append(problems, fmt.Sprintf("반복/조건이 너무 깊게 중첩되어 있습니다 (%d단계, 최대 %d단계)", s.Depth, c.MaxDepth))
+		}
+	}
+	return problems
+}
+
+// ConstraintError는 풀이가 문제의 구조 제한을 어겼을 때 Compile이 반환합니다.
+// 모든 테스트케이스가 ConstraintViolation으로 판정됩니다.
+type ConstraintError struct {
+	Problems []string
+}
+
+func (e *ConstraintError) Error() string {
+	return "조건 위반: " + strings.Join(e.Problems, "; ")
+}
+
+// constrained는 실행 전에 구조 제한을 확인하는 Executor입니다
+type constrained struct {
+	Executor
+	constraints Constraints
+	structure   *Structure
+}
+
+// Constrained는 Compile 단계에서 structure가 constraints를 지키는지도 확인하는
+// Executor를 만듭니다. 문법 오류가 있으면 제한보다 먼저 CompileError로 판정합니다.
+func Constrained(exec Executor, constraints Constraints, structure *Structure) Executor {
+	return &constrained{Executor: exec, constraints: constraints, structure: structure}
+}
+
+func (c *constrained) Compile() error {
+	if err := c.Executor.Compile(); err != nil {
+		return err
+	}
+	if problems := c.constraints.Check(c.structure); len(problems) > 0 {
+		return &ConstraintError{Problems: problems}
+	}
+	return nil
+}
+
+// AnalyzeScript는 JavaScript 코드의 문법 트리에서 풀이의 모양을 읽습니다.
+// prompt() 호출은 input, console.log 호출은 output, 대입·변수 선언·증감은 process,
+// 반복문은 loop, if 문과 삼항 연산자는 if 블록으로 셉니다.
+func AnalyzeScript(code string) (*Structure, error) {
+	if err := checkCodeSize(code); err != nil {
+		return nil, err
+	}
+	program, err := parser.ParseFile(nil, "", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("문법 오류: %v", err)
+	}
+	s := &Structure{Blocks: make(map[string]int)}
+	walkScript(program, func(node ast.Node, depth int) int {
+		return countScriptNode(node, depth, s)
+	})
+	s.Blocks[BlockDecision] = s.Blocks[BlockLoop] + s.Blocks[BlockIf]
+	return s, nil
+}
+
+var astPackage = reflect.TypeOf(ast.Program{}).PkgPath()
+
+// walkScript는 문법 트리의 노드마다 visit을 부릅니다. visit은 노드와 부모에게서
+// 받은 깊이를 받아 자식들에게 넘길 깊이를 돌려줍니다.
+func walkScript(program *ast.Program, visit func(node ast.Node, depth int) int) {
+	w := &scriptWalker{visit: visit, seen: make(map[uintptr]bool)}
+	w.walk(reflect.ValueOf(program), 0)
+}
+
+// scriptWalker는 문법 트리를 돕니다. goja/ast에는 트리를 도는 함수가 없으므로
+// ast 패키지의 구조체 필드를 reflect로 따라갑니다. var 선언은 함수의
+// DeclarationList에도 한 번 더 들어 있으므로 그 필드는 따라가지 않고, 혹시 다른
+// 곳에서 같은 노드를 가리켜도 한 번만 방문합니다.
+type scriptWalker struct {
+	visit func(node ast.Node, depth int) int
+	seen  map[uintptr]bool
+}
+
+func (w *scriptWalker) walk(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			w.walk(v.Elem(), depth)
+		}
+	case reflect.Pointer:
+		if v.IsNil() || w.seen[v.Pointer()] {
+			return
+		}
+		w.seen[v.Pointer()] = true
+		if node, ok := v.Interface().(ast.Node); ok {
+			depth = w.visit(node, depth)
+		}
+		w.walk(v.Elem(), depth)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			w.walk(v.Index(i), depth)
+		}
+	case reflect.Struct:
+		if v.Type().PkgPath() != astPackage {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() && f.Name != "DeclarationList" {
+				w.walk(v.Field(i), depth)
+			}
+		}
+	}
+}
+
+// scriptInputs는 스크립트에서 입력 블록으로 세는 내장 함수입니다
+var scriptInputs = map[string]bool{
+	"prompt": true, "readLine": true, "readInt": true, "readAll": true, "hasInput": true,
+}
+
+// countScriptNode는 노드 하나를 세고 그 자식들의 중첩 깊이를 돌려줍니다
+func countScriptNode(node ast.Node, depth int, s *Structure) int {
+	block := ""
+	switch n := node.(type) {
+	case *ast.ForStatement, *ast.ForInStatement, *ast.ForOfStatement, *ast.WhileStatement, *ast.DoWhileStatement:
+		block = BlockLoop
+	case *ast.IfStatement, *ast.ConditionalExpression:
+		block = BlockIf
+	case *ast.AssignExpression:
+		block = BlockProcess
+	case *ast.Binding:
+		if n.Initializer != nil {
+			block = BlockProcess
+		}
+	case *ast.UnaryExpression:
+		if n.Operator == token.INCREMENT || n.Operator == token.DECREMENT {
+			block = BlockProcess
+		}
+	case *ast.CallExpression:
+		switch callee := n.Callee.(type) {
+		case *ast.Identifier:
+			if scriptInputs[callee.Name.String()] {
+				block = BlockInput
+			} else if callee.Name == "print" {
+				block = BlockOutput
+			}
+		case *ast.DotExpression:
+			if obj, ok := callee.Left.(*ast.Identifier); ok && obj.Name == "console" && callee.Identifier.Name == "log" {
+				block = BlockOutput
+			}
+		}
+	}
+	if block == "" {
+		return depth
+	}
+	s.Blocks[block]++
+	if block == BlockLoop || block == BlockIf {
+		depth++
+		s.Depth = max(s.Depth, depth)
+	}
+	return depth
+}
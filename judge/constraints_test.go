@@ -0,0 +1,143 @@
+package judge
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeScript(t *testing.T) {
+	code := `
+var n = Number(prompt());
+var sum = 0;
+for (var i = 1; i <= n; i++) {
+	if (i % 2 === 0) {
+		sum += i;
+	}
+}
+console.log(sum > 10 ? "big" : sum);
+`
+	s, err := AnalyzeScript(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		BlockInput:    1,
+		BlockOutput:   1,
+		BlockProcess:  5, // n, sum, i 선언과 i++, sum +=
+		BlockLoop:     1,
+		BlockIf:       2,
+		BlockDecision: 3,
+	}
+	if !reflect.DeepEqual(s.Blocks, want) {
+		t.Errorf("blocks = %v, want %v", s.Blocks, want)
+	}
+	if s.Depth != 2 {
+		t.Errorf("depth = %d, want 2", s.Depth)
+	}
+
+	if _, err := AnalyzeScript("for ("); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
+
+func TestAnalyzeScriptIOBuiltins(t *testing.T) {
+	s, err := AnalyzeScript(`
+var n = readInt();
+var name = readLine();
+while (hasInput()) print(readAll().length);
+print(name, n);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Blocks[BlockInput] != 4 || s.Blocks[BlockOutput] != 2 {
+		t.Errorf("blocks = %v, want 4 inputs and 2 outputs", s.Blocks)
+	}
+}
+
+func TestConstrainedIOBuiltins(t *testing.T) {
+	j := NewJudge(1, 1)
+	code := "var n = readInt(); print(n * 2);"
+	s, err := AnalyzeScript(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []TestCase{{ID: 1, Input: []string{"21"}, Output: []string{"42"}}}
+
+	tests := []struct {
+		name        string
+		constraints Constraints
+		want        Verdict
+	}{
+		{"input and output required", Constraints{Required: []string{BlockInput, BlockOutput}}, Accepted},
+		{"input forbidden", Constraints{Forbidden: []string{BlockInput}}, ConstraintViolation},
+		{"output forbidden", Constraints{Forbidden: []string{BlockOutput}}, ConstraintViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := j.Run(context.Background(), Constrained(Script(code), tt.constraints, s), cases, Limits{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results[0].Verdict != tt.want {
+				t.Errorf("verdict = %s (%v), want %s", results[0].Verdict, results[0].Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstrained(t *testing.T) {
+	j := NewJudge(1, 1)
+	formula := "var n = Number(prompt()); console.log(n * (n + 1) / 2);"
+	s, err := AnalyzeScript(formula)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []TestCase{{ID: 1, Input: []string{"10"}, Output: []string{"55"}}}
+
+	tests := []struct {
+		name        string
+		constraints Constraints
+		want        Verdict
+	}{
+		{"no constraints", Constraints{}, Accepted},
+		{"loop required", Constraints{Required: []string{BlockLoop}}, ConstraintViolation},
+		{"output forbidden", Constraints{Forbidden: []string{BlockOutput}}, ConstraintViolation},
+		{"too many blocks", Constraints{MaxNodes: 2}, ConstraintViolation},
+		{"enough blocks", Constraints{MaxNodes: 3}, Accepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := j.Run(context.Background(), Constrained(Script(formula), tt.constraints, s), cases, Limits{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results[0].Verdict != tt.want {
+				t.Errorf("verdict = %s (%v), want %s", results[0].Verdict, results[0].Error, tt.want)
+			}
+		})
+	}
+
+	// 문법 오류는 구조 제한보다 먼저 판정한다
+	results, err := j.Run(context.Background(), Constrained(Script("for ("), Constraints{Required: []string{BlockLoop}}, s), cases, Limits{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Verdict != CompileError {
+		t.Errorf("verdict = %s, want %s", results[0].Verdict, CompileError)
+	}
+}
+
+func TestConstraintsValidate(t *testing.T) {
+	invalid := []Constraints{
+		{Required: []string{"goto"}},
+		{Required: []string{BlockLoop}, Forbidden: []string{BlockLoop}},
+		{MaxDepth: -1},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", c)
+		}
+	}
+}
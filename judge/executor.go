@@ -0,0 +1,204 @@
+// judge/executor.go
+package judge
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Executor는 제출된 프로그램을 실행하는 방법입니다. JavaScript 코드는 goja VM에서
+// 실행하고(Script), 순서도는 flowchart 패키지의 인터프리터가 블록 단위로 실행합니다.
+type Executor interface {
+	// Compile은 채점을 시작하기 전에 한 번 불립니다. 에러를 반환하면 모든
+	// 테스트케이스가 CompileError가 됩니다.
+	Compile() error
+	// Prepare는 테스트케이스 하나를 env로 입출력하며 실행할 준비를 합니다.
+	Prepare(env *Env) Execution
+}
+
+// Execution은 테스트케이스 하나의 실행입니다.
+type Execution interface {
+	// Run은 프로그램을 끝까지 실행합니다. Interrupt로 멈췄다면 넘겨받은 사유를,
+	// Env가 돌려준 에러로 멈췄다면 그 에러를 감싸서 반환합니다.
+	Run() error
+	// Interrupt는 다른 고루틴에서 실행을 멈춥니다. 여러 번 불려도 됩니다.
+	Interrupt(reason error)
+}
+
+// NodeError는 실행 오류가 난 위치를 순서도 블록으로 알려주는 에러가 구현합니다.
+type NodeError interface {
+	error
+	NodeID() string
+}
+
+// Env는 실행 중인 프로그램이 채점기와 주고받는 입출력과 실행 단계 예산입니다.
+// 실행 고루틴에서만 씁니다.
+type Env struct {
+	input     []string
+	next      int
+	output    []string
+	partial   strings.Builder // 줄바꿈 없이 출력해서 아직 끝나지 않은 줄
+	bytes     int
+	steps     int
+	limits    Limits
+	exceeded  bool
+	interrupt func(error)
+	trace     *Trace // 실행 기록을 남길 때만 있음
+	rand      *rand.Rand
+	now       time.Time
+}
+
+func newEnv(tc TestCase, limits Limits) *Env {
+	seed := tc.Seed
+	if seed == 0 {
+		seed = int64(tc.ID)
+	}
+	return &Env{
+		input:  tc.Input,
+		output: make([]string, 0),
+		limits: limits,
+		rand:   rand.New(rand.NewSource(seed)),
+		now:    tc.Now,
+	}
+}
+
+// Random은 [0, 1) 범위의 난수를 돌려줍니다. 테스트케이스의 시드로 만든 수열이라
+// 같은 테스트케이스에서는 항상 같은 순서로 나옵니다.
+func (e *Env) Random() float64 {
+	return e.rand.Float64()
+}
+
+// Now는 프로그램이 보는 현재 시각입니다. 테스트케이스에 시각이 정해져 있으면
+// 실행하는 동안 그 시각에 멈춰 있습니다.
+func (e *Env) Now() time.Time {
+	if e.now.IsZero() {
+		return time.Now()
+	}
+	return e.now
+}
+
+// errNoInput은 입력을 모두 읽은 뒤에 더 읽으려 할 때의 에러입니다. 판정은
+// RuntimeError이고 메시지는 "EOF"입니다.
+var errNoInput = errors.New("EOF: 입력을 모두 읽었습니다")
+
+// ReadLine은 입력 한 줄을 읽습니다. 남은 입력이 없으면 errNoInput을 반환합니다.
+func (e *Env) ReadLine() (string, error) {
+	if e.next >= len(e.input) {
+		return "", errNoInput
+	}
+	line := e.input[e.next]
+	e.next++
+	if e.trace != nil {
+		e.trace.add(TraceEvent{Kind: TraceInput, Value: line})
+	}
+	return line, nil
+}
+
+// HasInput은 아직 읽지 않은 입력이 남았는지 알려줍니다
+func (e *Env) HasInput() bool {
+	return e.next < len(e.input)
+}
+
+// ReadAll은 남은 입력을 모두 읽어 줄들을 돌려줍니다. 남은 입력이 없으면 빈
+// 슬라이스입니다.
+func (e *Env) ReadAll() []string {
+	lines := make([]string, 0, len(e.input)-e.next)
+	for e.HasInput() {
+		line, _ := e.ReadLine()
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WriteLine은 출력 한 줄을 기록합니다. Write로 쓰다 만 줄이 있으면 그 뒤에 이어
+// 씁니다. 출력 제한을 넘으면 더 쌓지 않고 실행을 멈춥니다.
+func (e *Env) WriteLine(line string) {
+	if e.exceeded || !e.count(len(line)+1) {
+		return
+	}
+	e.endLine(line)
+}
+
+// Write는 줄바꿈 없이 출력합니다. text 안의 줄바꿈에서 줄이 끝나고, 마지막
+// 줄바꿈 뒤의 글자는 다음 출력에 이어집니다.
+func (e *Env) Write(text string) {
+	if e.exceeded || !e.count(len(text)) {
+		return
+	}
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		if !e.endLine(text[:i]) {
+			return
+		}
+		text = text[i+1:]
+	}
+	e.partial.WriteString(text)
+}
+
+// count는 출력 바이트 수를 더하고, 제한을 넘으면 실행을 멈추고 false를 반환합니다
+func (e *Env) count(bytes int) bool {
+	e.bytes += bytes
+	if e.limits.OutputBytes > 0 && e.bytes > e.limits.OutputBytes {
+		e.exceed()
+		return false
+	}
+	return true
+}
+
+// endLine은 쓰다 만 줄 뒤에 text를 붙여 한 줄을 끝냅니다. 줄 수 제한을 넘으면
+// 실행을 멈추고 false를 반환합니다.
+func (e *Env) endLine(text string) bool {
+	if e.limits.OutputLines > 0 && len(e.output) >= e.limits.OutputLines {
+		e.exceed()
+		return false
+	}
+	line := text
+	if e.partial.Len() > 0 {
+		line = e.partial.String() + text
+		e.partial.Reset()
+	}
+	e.output = append(e.output, line)
+	if e.trace != nil {
+		e.trace.add(TraceEvent{Kind: TraceOutput, Value: line})
+	}
+	return true
+}
+
+func (e *Env) exceed() {
+	e.exceeded = true
+	e.interrupt(errOutputLimit)
+}
+
+// flush는 실행이 끝난 뒤 쓰다 만 줄을 마지막 줄로 끝냅니다. 그 줄이 줄 수
+// 제한을 넘으면 errOutputLimit을 반환합니다.
+func (e *Env) flush() error {
+	if e.exceeded || e.partial.Len() == 0 {
+		return nil
+	}
+	if e.limits.OutputLines > 0 && len(e.output) >= e.limits.OutputLines {
+		e.exceeded = true
+		return errOutputLimit
+	}
+	e.endLine("")
+	return nil
+}
+
+// Step은 실행 단계 하나를 셉니다. Limits.Steps를 넘으면 errStepLimit을 반환하며,
+// 실행기는 이 에러를 그대로 돌려주고 멈춰야 합니다.
+func (e *Env) Step() error {
+	e.steps++
+	if e.limits.Steps > 0 && e.steps > e.limits.Steps {
+		return errStepLimit
+	}
+	return nil
+}
+
+// Output은 지금까지 출력한 줄들입니다
+func (e *Env) Output() []string {
+	return e.output
+}
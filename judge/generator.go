@@ -0,0 +1,351 @@
+// judge/generator.go
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// 생성기 입력 값의 종류
+const (
+	InputInt    = "int"    // Min 이상 Max 이하의 정수
+	InputFloat  = "float"  // Min 이상 Max 이하의 실수, 소수점 아래 Decimals자리
+	InputString = "string" // 길이가 Min 이상 Max 이하인 Alphabet 글자들의 문자열
+	InputChoice = "choice" // Choices 중 하나
+)
+
+// 생성기 제한
+const (
+	defaultGeneratedCases = 10
+	maxGeneratedCases     = 100
+	maxGeneratedValues    = 1000            // 입력 한 항목이 만드는 값의 최대 개수
+	maxGeneratedLines     = 100000          // generate 함수가 한 제출에 만드는 입력 줄 수의 합
+	maxGeneratedBytes     = 4 << 20         // generate 함수가 한 제출에 만드는 입력 크기의 합
+	generatorTimeout      = time.Second     // generate 함수 한 번의 실행 시간
+	generateDeadline      = 5 * time.Second // 한 제출의 테스트케이스를 모두 만드는 시간
+)
+
+var (
+	errGeneratorTimeout = errors.New("generate 함수 시간 초과")
+	errGenerateDeadline = errors.New("테스트케이스 생성 시간 초과")
+)
+
+// InputSpec은 선언형 생성기의 입력 한 항목입니다. Count개의 값을 한 줄에 하나씩
+// 만들고, Inline이면 공백으로 이어 한 줄로 만듭니다.
+type InputSpec struct {
+	Type     string   `json:"type"`
+	Min      float64  `json:"min,omitempty"`
+	Max      float64  `json:"max,omitempty"`
+	Count    int      `json:"count,omitempty"`    // 값의 개수 (0이면 1)
+	Inline   bool     `json:"inline,omitempty"`   // 값들을 한 줄에 공백으로 이어 쓸지
+	Decimals int      `json:"decimals,omitempty"` // float: 소수점 아래 자릿수
+	Alphabet string   `json:"alphabet,omitempty"` // string: 쓸 글자들 (없으면 영어 소문자)
+	Choices  []string `json:"choices,omitempty"`  // choice: 고를 값들
+}
+
+// GeneratorSpec은 문제에 저장하는 무작위 테스트케이스 생성기입니다. Inputs로 입력의
+// 모양을 선언하거나, Script에 generate(rand, index) 함수를 정의합니다. 정답은
+// 참조 풀이를 실행해서 만듭니다.
+type GeneratorSpec struct {
+	Count  int         `json:"count,omitempty"`  // 제출마다 만들 테스트케이스 수 (0이면 10)
+	Points int         `json:"points,omitempty"` // 만든 테스트케이스 하나의 배점 (0이면 1점)
+	Inputs []InputSpec `json:"inputs,omitempty"`
+	Script string      `json:"script,omitempty"`
+}
+
+// Generator는 시드에서 테스트케이스 입력들을 만듭니다. 같은 시드로는 항상 같은
+// 입력을 만듭니다. ctx가 끝나면 만들기를 멈추고 그 사유를 반환합니다.
+type Generator interface {
+	Generate(ctx context.Context, seed int64) ([][]string, error)
+}
+
+// Generate는 spec의 생성기로 seed의 테스트케이스 입력들을 만듭니다. 생성기
+// 코드도 채점과 같은 대기열과 워커에서 실행하고, 모든 케이스를 합쳐
+// generateDeadline 안에 끝나야 합니다. 대기열이 가득 차 있으면 ErrQueueFull을
+// 반환합니다. spec은 문제를 저장할 때 NewGenerator로 확인했다고 보고, 시험 삼아
+// 만들어 보는 단계는 건너뜁니다.
+func (j *Judge) Generate(ctx context.Context, spec *GeneratorSpec, seed int64) ([][]string, error) {
+	select {
+	case j.queue <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+	defer func() { <-j.queue }()
+	// 메모리 제한이 있는 VM이 혼자 실행되는 동안에는 생성기 코드도 기다린다
+	release, err := j.acquire(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	gen, err := newGenerator(spec)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, generateDeadline, errGenerateDeadline)
+	defer cancel()
+	return gen.Generate(ctx, seed)
+}
+
+// NewGenerator는 생성기 설정을 확인하고 Generator를 만듭니다. generate 함수는
+// 시험 삼아 입력을 한 번 만들어 봅니다.
+func NewGenerator(spec *GeneratorSpec) (Generator, error) {
+	gen, err := newGenerator(spec)
+	if err != nil {
+		return nil, err
+	}
+	if g, ok := gen.(scriptGenerator); ok {
+		g.count = 1
+		if _, err := g.Generate(context.Background(), 1); err != nil {
+			return nil, err
+		}
+	}
+	return gen, nil
+}
+
+// newGenerator는 NewGenerator와 같지만 generate 함수를 실행해 보지 않습니다
+func newGenerator(spec *GeneratorSpec) (Generator, error) {
+	count := spec.Count
+	if count == 0 {
+		count = defaultGeneratedCases
+	}
+	if count < 0 || count > maxGeneratedCases {
+		return nil, fmt.Errorf("생성할 테스트케이스 수는 1개 이상 %d개 이하여야 합니다", maxGeneratedCases)
+	}
+	if spec.Points < 0 {
+		return nil, errors.New("배점은 0 이상이어야 합니다")
+	}
+	switch {
+	case spec.Script != "" && len(spec.Inputs) > 0:
+		return nil, errors.New("생성기는 inputs와 script 중 하나만 지정해야 합니다")
+	case spec.Script != "":
+		return newScriptGenerator(spec.Script, count)
+	case len(spec.Inputs) > 0:
+		for i, in := range spec.Inputs {
+			if err := in.validate(); err != nil {
+				return nil, fmt.Errorf("입력 #%d: %v", i+1, err)
+			}
+		}
+		return inputGenerator{inputs: spec.Inputs, count: count}, nil
+	}
+	return nil, errors.New("생성기에 inputs나 script가 필요합니다")
+}
+
+// caseRand는 index번째 테스트케이스에 쓸 난수 생성기입니다. 케이스마다 따로 만들어
+// 한 케이스의 입력 모양이 바뀌어도 다른 케이스의 입력은 그대로입니다.
+func caseRand(seed int64, index int) *rand.Rand {
+	return rand.New(rand.NewSource(seed + int64(index)*0x9E3779B9))
+}
+
+func (in InputSpec) validate() error {
+	if in.Count < 0 || in.Count > maxGeneratedValues {
+		return fmt.Errorf("값의 개수는 0 이상 %d 이하여야 합니다", maxGeneratedValues)
+	}
+	switch in.Type {
+	case InputInt:
+		if in.Min != math.Trunc(in.Min) || in.Max != math.Trunc(in.Max) {
+			return errors.New("정수 범위는 정수로 지정해야 합니다")
+		}
+		if math.Abs(in.Min) > 1<<53 || math.Abs(in.Max) > 1<<53 {
+			return errors.New("정수 범위가 너무 큽니다")
+		}
+	case InputFloat:
+		if in.Decimals < 0 || in.Decimals > 10 {
+			return errors.New("소수점 아래 자릿수는 0 이상 10 이하여야 합니다")
+		}
+	case InputString:
+		if in.Min < 0 || in.Max > maxGeneratedValues || in.Min != math.Trunc(in.Min) || in.Max != math.Trunc(in.Max) {
+			return fmt.Errorf("문자열 길이는 0 이상 %d 이하의 정수여야 합니다", maxGeneratedValues)
+		}
+	case InputChoice:
+		if len(in.Choices) == 0 {
+			return errors.New("choices가 비어 있습니다")
+		}
+		return nil
+	default:
+		return fmt.Errorf("알 수 없는 입력 종류 %q", in.Type)
+	}
+	if in.Min > in.Max {
+		return errors.New("min이 max보다 큽니다")
+	}
+	return nil
+}
+
+// value는 항목의 값 하나를 만듭니다
+func (in InputSpec) value(r *rand.Rand) string {
+	switch in.Type {
+	case InputInt:
+		lo, hi := int64(in.Min), int64(in.Max)
+		return strconv.FormatInt(lo+r.Int63n(hi-lo+1), 10)
+	case InputFloat:
+		return strconv.FormatFloat(in.Min+r.Float64()*(in.Max-in.Min), 'f', in.Decimals, 64)
+	case InputString:
+		alphabet := []rune(in.Alphabet)
+		if len(alphabet) == 0 {
+			alphabet = []rune("abcdefghijklmnopqrstuvwxyz")
+		}
+		n := int(in.Min) + r.Intn(int(in.Max-in.Min)+1)
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteRune(alphabet[r.Intn(len(alphabet))])
+		}
+		return b.String()
+	}
+	return in.Choices[r.Intn(len(in.Choices))]
+}
+
+type inputGenerator struct {
+	inputs []InputSpec
+	count  int
+}
+
+func (g inputGenerator) Generate(_ context.Context, seed int64) ([][]string, error) {
+	cases := make([][]string, g.count)
+	for i := range cases {
+		r := caseRand(seed, i)
+		var lines []string
+		for _, in := range g.inputs {
+			values := make([]string, max(in.Count, 1))
+			for k := range values {
+				values[k] = in.value(r)
+			}
+			if in.Inline {
+				lines = append(lines, strings.Join(values, " "))
+			} else {
+				lines = append(lines, values...)
+			}
+		}
+		cases[i] = lines
+	}
+	return cases, nil
+}
+
+// scriptGenerator는 선생님이 작성한 generate 함수로 입력을 만듭니다
+type scriptGenerator struct {
+	program *goja.Program
+	count   int
+}
+
+// newScriptGenerator는 generate 함수 코드를 컴파일합니다
+func newScriptGenerator(code string, count int) (Generator, error) {
+	program, err := compileSandboxed("generator", code)
+	if err != nil {
+		return nil, fmt.Errorf("generate 함수 문법 오류: %v", err)
+	}
+	return scriptGenerator{program: program, count: count}, nil
+}
+
+// generatedSize는 generate 함수가 앞으로 더 만들 수 있는 입력 줄 수와 크기입니다
+type generatedSize struct {
+	lines, bytes int
+}
+
+// take는 줄 lines개, 크기 bytes만큼을 덜어냅니다. 남은 양보다 많으면 에러를 반환합니다.
+func (s *generatedSize) take(lines, bytes int) error {
+	if lines > s.lines || bytes > s.bytes {
+		return fmt.Errorf("생성한 입력이 너무 큽니다 (모든 케이스를 합쳐 최대 %d줄, %d바이트)", maxGeneratedLines, maxGeneratedBytes)
+	}
+	s.lines -= lines
+	s.bytes -= bytes
+	return nil
+}
+
+// Generate는 케이스마다 새 샌드박스 VM에서 generate(rand, index)를 부릅니다. rand에는
+// int(min, max), float(min, max), pick(array), random()이 있고, Math.random도
+// 같은 시드를 씁니다. generate는 입력 줄들의 배열이나 줄바꿈으로 나눈 문자열을
+// 돌려줍니다. 모든 케이스를 합친 입력은 maxGeneratedLines줄, maxGeneratedBytes
+// 바이트를 넘을 수 없습니다.
+func (g scriptGenerator) Generate(ctx context.Context, seed int64) ([][]string, error) {
+	cases := make([][]string, g.count)
+	left := generatedSize{lines: maxGeneratedLines, bytes: maxGeneratedBytes}
+	for i := range cases {
+		lines, err := g.generate(ctx, caseRand(seed, i), i, &left)
+		if err != nil {
+			return nil, fmt.Errorf("테스트케이스 #%d: %w", i+1, err)
+		}
+		cases[i] = lines
+	}
+	return cases, nil
+}
+
+func (g scriptGenerator) generate(ctx context.Context, r *rand.Rand, index int, left *generatedSize) (lines []string, err error) {
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	vm := newSandbox()
+	vm.SetRandSource(r.Float64)
+	timer := time.AfterFunc(generatorTimeout, func() { vm.Interrupt(errGeneratorTimeout) })
+	defer timer.Stop()
+	stop := context.AfterFunc(ctx, func() { vm.Interrupt(context.Cause(ctx)) })
+	defer stop()
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("generate 함수 오류: %v", rec)
+		}
+	}()
+
+	if _, err := vm.RunProgram(g.program); err != nil {
+		return nil, fmt.Errorf("generate 함수 코드 실행 오류: %v", err)
+	}
+	generate, ok := goja.AssertFunction(vm.Get("generate"))
+	if !ok {
+		return nil, errors.New("generate(rand, index) 함수가 정의되어 있지 않습니다")
+	}
+
+	random := vm.NewObject()
+	random.Set("random", r.Float64)
+	random.Set("int", func(lo, hi int64) int64 {
+		if hi < lo {
+			panic(vm.NewTypeError("rand.int: min이 max보다 큽니다"))
+		}
+		return lo + r.Int63n(hi-lo+1)
+	})
+	random.Set("float", func(lo, hi float64) float64 { return lo + r.Float64()*(hi-lo) })
+	random.Set("pick", func(items []goja.Value) goja.Value {
+		if len(items) == 0 {
+			panic(vm.NewTypeError("rand.pick: 빈 배열입니다"))
+		}
+		return items[r.Intn(len(items))]
+	})
+
+	ret, err := generate(goja.Undefined(), random, vm.ToValue(index))
+	if err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			if cause, ok := interrupted.Value().(error); ok {
+				return nil, cause
+			}
+			return nil, errGeneratorTimeout
+		}
+		return nil, fmt.Errorf("generate 함수 오류: %v", err)
+	}
+
+	switch v := ret.Export().(type) {
+	case string:
+		if err := left.take(strings.Count(v, "\n")+1, len(v)); err != nil {
+			return nil, err
+		}
+		return strings.Split(v, "\n"), nil
+	case []interface{}:
+		if err := left.take(len(v), 0); err != nil {
+			return nil, err
+		}
+		lines = make([]string, len(v))
+		for k, item := range v {
+			lines[k] = vm.ToValue(item).String()
+			if err := left.take(0, len(lines[k])); err != nil {
+				return nil, err
+			}
+		}
+		return lines, nil
+	}
+	return nil, errors.New("generate 함수는 입력 줄들의 배열이나 문자열을 돌려줘야 합니다")
+}
@@ -0,0 +1,161 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInputGenerator(t *testing.T) {
+	spec := &GeneratorSpec{Count: 20, Inputs: []InputSpec{
+		{Type: InputInt, Min: 1, Max: 1000, Count: 2},
+		{Type: InputChoice, Choices: []string{"a", "b"}},
+		{Type: InputString, Min: 3, Max: 3, Alphabet: "xy", Count: 2, Inline: true},
+	}}
+	g, err := NewGenerator(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, err := g.Generate(context.Background(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 20 {
+		t.Fatalf("len = %d, want 20", len(cases))
+	}
+	for _, lines := range cases {
+		if len(lines) != 4 {
+			t.Fatalf("lines = %q, want 4 lines", lines)
+		}
+		for _, s := range lines[:2] {
+			if n, err := strconv.Atoi(s); err != nil || n < 1 || n > 1000 {
+				t.Errorf("int %q out of range", s)
+			}
+		}
+		if lines[2] != "a" && lines[2] != "b" {
+			t.Errorf("choice = %q", lines[2])
+		}
+		if words := strings.Fields(lines[3]); len(words) != 2 || len(words[0]) != 3 || strings.Trim(words[0], "xy") != "" {
+			t.Errorf("strings = %q", lines[3])
+		}
+	}
+
+	again, _ := g.Generate(context.Background(), 42)
+	if !reflect.DeepEqual(cases, again) {
+		t.Error("same seed produced different inputs")
+	}
+	other, _ := g.Generate(context.Background(), 43)
+	if reflect.DeepEqual(cases, other) {
+		t.Error("different seeds produced the same inputs")
+	}
+}
+
+func TestScriptGenerator(t *testing.T) {
+	g, err := NewGenerator(&GeneratorSpec{Count: 5, Script: `
+function generate(rand, index) {
+	var n = rand.int(1, 5);
+	var lines = [n];
+	for (var i = 0; i < n; i++) lines.push(rand.pick(["x", "y"]) + Math.floor(Math.random() * 10));
+	return lines;
+}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, err := g.Generate(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lines := range cases {
+		n, err := strconv.Atoi(lines[0])
+		if err != nil || len(lines) != n+1 {
+			t.Errorf("lines = %q", lines)
+		}
+	}
+	again, _ := g.Generate(context.Background(), 7)
+	if !reflect.DeepEqual(cases, again) {
+		t.Error("same seed produced different inputs")
+	}
+}
+
+func TestNewGeneratorRejectsInvalidSpecs(t *testing.T) {
+	invalid := []*GeneratorSpec{
+		{},
+		{Count: maxGeneratedCases + 1, Inputs: []InputSpec{{Type: InputInt, Max: 1}}},
+		{Points: -1, Inputs: []InputSpec{{Type: InputInt, Max: 1}}},
+		{Inputs: []InputSpec{{Type: InputInt, Min: 5, Max: 1}}},
+		{Inputs: []InputSpec{{Type: InputInt, Min: 0.5, Max: 1}}},
+		{Inputs: []InputSpec{{Type: InputChoice}}},
+		{Inputs: []InputSpec{{Type: "date"}}},
+		{Script: "function generate( {"},
+		{Script: "var x = 1;"},
+		{Script: "function generate() { for (;;) {} }"},
+		{Script: "function generate() { return 1; }"},
+		{Script: "function generate() { return [globalThis.eval('1')]; }"},
+		{Script: "function generate() { return []; }", Inputs: []InputSpec{{Type: InputInt}}},
+	}
+	for _, spec := range invalid {
+		if _, err := NewGenerator(spec); err == nil {
+			t.Errorf("%+v: expected an error", spec)
+		}
+	}
+}
+
+func TestScriptGeneratorStopsWhenContextEnds(t *testing.T) {
+	g, err := NewGenerator(&GeneratorSpec{Count: 50, Script: `
+function generate(rand, index) {
+	var t = Date.now();
+	while (index > 0 && Date.now() - t < 500) {}
+	return ["1"];
+}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = g.Generate(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("generation kept running for %v after the deadline", elapsed)
+	}
+}
+
+func TestScriptGeneratorLimitsOutputSize(t *testing.T) {
+	tests := map[string]string{
+		"too many lines": "function generate() { return new Array(20000).fill('1'); }",
+		"too many bytes": "function generate() { return ['x'.repeat(100000)]; }",
+		"long string":    "function generate() { return 'x'.repeat(100000) + '\\n1'; }",
+	}
+	for name, code := range tests {
+		t.Run(name, func(t *testing.T) {
+			g, err := NewGenerator(&GeneratorSpec{Count: 100, Script: code})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.Generate(context.Background(), 1); err == nil || !strings.Contains(err.Error(), "너무 큽니다") {
+				t.Errorf("err = %v, want the output to be too large", err)
+			}
+		})
+	}
+}
+
+func TestJudgeGenerateUsesQueue(t *testing.T) {
+	j := NewJudge(1, 1)
+	spec := &GeneratorSpec{Count: 3, Inputs: []InputSpec{{Type: InputInt, Max: 9}}}
+	cases, err := j.Generate(context.Background(), spec, 1)
+	if err != nil || len(cases) != 3 {
+		t.Fatalf("cases = %q, err = %v", cases, err)
+	}
+
+	j.queue <- struct{}{}
+	defer func() { <-j.queue }()
+	if _, err := j.Generate(context.Background(), spec, 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("err = %v, want ErrQueueFull", err)
+	}
+}
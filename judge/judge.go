@@ -2,127 +2,262 @@
 package judge
 
 import (
-	"fmt"
-	"strings"
+	"context"
+	"errors"
+	"runtime"
 	"sync"
 	"time"
-
-	"github.com/dop251/goja"
 )
 
-// TestCase 구조체 정의
+// Judge는 프로세스 전체가 함께 쓰는 채점 서비스입니다. 동시에 실행되는 VM 수는
+// workers개로, 대기 중이거나 실행 중인 제출 수는 queueSize개로 제한합니다.
 type Judge struct {
-	timeout time.Duration
-	workers int
+	workers chan struct{}
+	queue   chan struct{}
 }
 
-func NewJudge(timeout time.Duration, workers int) *Judge {
+// ErrQueueFull은 채점 대기열이 가득 차 제출을 받을 수 없을 때 반환됩니다
+var ErrQueueFull = errors.New("채점 대기열이 가득 찼습니다")
+
+func NewJudge(workers, queueSize int) *Judge {
 	return &Judge{
-		timeout: timeout,
-		workers: workers,
+		workers: make(chan struct{}, workers),
+		queue:   make(chan struct{}, queueSize),
+	}
+}
+
+// indexedResult는 채점이 끝난 순서대로 전달되는 결과와 그 테스트케이스의 위치입니다
+type indexedResult struct {
+	index  int
+	result TestResult
+}
+
+// Run은 모든 테스트케이스를 병렬로 채점하고 cases와 같은 순서로 결과를 돌려줍니다.
+// ctx가 취소되면 실행 중인 VM을 멈추고 남은 케이스는 실행하지 않습니다.
+// 대기열이 가득 차 있으면 기다리지 않고 ErrQueueFull을 반환합니다.
+func (j *Judge) Run(ctx context.Context, exec Executor, cases []TestCase, limits Limits, check Checker) ([]TestResult, error) {
+	ch, err := j.start(ctx, exec, cases, limits, check, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TestResult, len(cases))
+	for r := range ch {
+		results[r.index] = r.result
 	}
+	return results, nil
+}
+
+// Job은 Submit으로 대기열에 넣은 제출의 진행 상황입니다.
+type Job struct {
+	// Started는 첫 테스트케이스가 워커를 얻어 실행을 시작하면 닫힙니다.
+	Started <-chan struct{}
+	// Results는 테스트케이스가 끝나는 대로 결과를 받고, 모두 끝나면 닫힙니다.
+	// 호출한 쪽은 채널을 끝까지 읽어야 합니다.
+	Results <-chan TestResult
+}
+
+// Submit은 Run과 같지만 기다리지 않고 바로 반환하며, 결과는 Job으로 전달합니다.
+func (j *Judge) Submit(ctx context.Context, exec Executor, cases []TestCase, limits Limits, check Checker) (*Job, error) {
+	started := make(chan struct{})
+	var once sync.Once
+	markStarted := func() { once.Do(func() { close(started) }) }
+
+	ch, err := j.start(ctx, exec, cases, limits, check, markStarted)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan TestResult)
+	go func() {
+		defer close(out)
+		defer markStarted() // 실행 전에 모두 취소된 경우에도 닫는다
+		for r := range ch {
+			out <- r.result
+		}
+	}()
+	return &Job{Started: started, Results: out}, nil
 }
 
-func (j *Judge) RunTestsParallel(code string, testCases []TestCase) []TestResult {
-	results := make([]TestResult, len(testCases))
+// start는 제출을 대기열에 넣고 테스트케이스별 실행을 시작합니다.
+// onStart는 테스트케이스가 워커를 얻을 때마다 호출됩니다.
+func (j *Judge) start(ctx context.Context, exec Executor, cases []TestCase, limits Limits, check Checker,
+	onStart func()) (<-chan indexedResult, error) {
+	select {
+	case j.queue <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	out := make(chan indexedResult, len(cases))
+
+	// 문법 오류와 구조 제한은 실행 전에 한 번만 확인한다
+	if err := exec.Compile(); err != nil {
+		for i, tc := range cases {
+			out <- indexedResult{i, compileFailure(tc, err)}
+		}
+		<-j.queue
+		close(out)
+		return out, nil
+	}
+
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, j.workers)
 
-	for i, tc := range testCases {
+	for i, tc := range cases {
 		wg.Add(1)
 		go func(i int, tc TestCase) {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			release, err := j.acquire(ctx, limits.MemoryBytes > 0)
+			if err != nil {
+				out <- indexedResult{i, canceledResult(tc)}
+				return
+			}
+			defer release()
+			onStart()
 
-			result := j.runSingleTest(code, tc)
-			results[i] = result
+			out <- indexedResult{i, runSingleTest(ctx, exec, newEnv(tc, limits), tc, limits, check)}
 		}(i, tc)
 	}
 
-	wg.Wait()
-	return results
+	go func() {
+		wg.Wait()
+		<-j.queue
+		close(out)
+	}()
+	return out, nil
 }
 
-func (j *Judge) runSingleTest(code string, tc TestCase) TestResult {
-	result := TestResult{
-		TestCaseID: tc.ID,
-		Passed:     false,
+// acquire는 VM 하나를 실행할 차례와 워커를 얻습니다. exclusive(메모리 제한이
+// 있는 VM)면 heapGate에서 다른 VM이 모두 끝나기를 기다립니다. 차례를 기다리는
+// 동안에는 워커를 잡지 않아 다른 제출이 그 워커를 쓸 수 있습니다. ctx가 끝나면
+// errCanceled를 반환합니다.
+func (j *Judge) acquire(ctx context.Context, exclusive bool) (release func(), err error) {
+	leave, err := heapGate.acquire(ctx, exclusive)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case j.workers <- struct{}{}:
+	case <-ctx.Done():
+		leave()
+		return nil, errCanceled
 	}
+	return func() {
+		<-j.workers
+		leave()
+	}, nil
+}
 
-	vm := goja.New()
-	done := make(chan bool)
-	outputs := make([]string, 0)
-	currentInputIndex := 0
+// runSingleTest는 테스트케이스 하나를 새 실행 환경에서 실행합니다. 결과는 실행
+// 고루틴이 한 번만 만들고, 시간·메모리·출력 제한이나 취소는 모두 Interrupt로
+// 실행을 멈춘 뒤 그 사유로 판정합니다. 호출한 쪽은 먼저 acquire로 차례를 얻어야
+// 합니다. 실행이 실제로 멈춘 뒤에 반환합니다.
+func runSingleTest(ctx context.Context, exec Executor, env *Env, tc TestCase, limits Limits, check Checker) TestResult {
+	var baseline uint64
+	if limits.MemoryBytes > 0 {
+		baseline = memoryBaseline(limits.MemoryBytes)
+	}
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, limits.Timeout, errTimeLimit)
+		defer cancel()
+	}
 
+	run := exec.Prepare(env)
+	env.interrupt = run.Interrupt
+	done := make(chan TestResult, 1)
 	go func() {
-		defer close(done)
-		defer func() {
-			if r := recover(); r != nil {
-				result.Error = fmt.Errorf("런타임 에러: %v", r)
-			}
-		}()
-
-		// console.log 함수 정의
-		console := map[string]interface{}{
-			"log": func(call goja.FunctionCall) goja.Value {
-				var output strings.Builder
-				for i, arg := range call.Arguments {
-					if i > 0 {
-						output.WriteString(" ")
-					}
-					output.WriteString(fmt.Sprint(arg))
-				}
-				outputs = append(outputs, output.String())
-				return goja.Undefined()
-			},
-		}
-		vm.Set("console", console)
+		done <- execute(run, env, tc, check)
+	}()
 
-		// prompt 함수 정의
-		vm.Set("prompt", func(call goja.FunctionCall) goja.Value {
-			if currentInputIndex >= len(tc.Input) {
-				panic("입력 초과")
-			}
-			input := tc.Input[currentInputIndex]
-			currentInputIndex++
-			return vm.ToValue(input)
+	// 메모리 감시
+	if limits.MemoryBytes > 0 {
+		stopWatch := make(chan struct{})
+		defer close(stopWatch)
+		go watchMemory(limits.MemoryBytes, baseline, stopWatch, func() {
+			run.Interrupt(errMemoryLimit)
 		})
+	}
 
-		// 코드 실행
-		_, err := vm.RunString(code)
-		if err != nil {
-			result.Error = fmt.Errorf("실행 오류: %v", err)
-			return
+	select {
+	case result := <-done:
+		// 감시가 GC를 기다리는 사이에 끝난 프로그램도 메모리를 판정한다. VM이 쓰던
+		// 메모리는 run이 살아 있는 동안 남아 있다.
+		if limits.MemoryBytes > 0 && result.Verdict.Completed() && exceededAfterRun(limits.MemoryBytes, baseline) {
+			result.Verdict, result.Message, result.Passed = MemoryLimitExceeded, "메모리 초과", false
 		}
-
-		// 출력 개수 확인
-		if len(outputs) != len(tc.Output) {
-			result.Message = fmt.Sprintf("출력 개수 불일치\n예상: %d개\n실제: %d개",
-				len(tc.Output), len(outputs))
-			return
+		runtime.KeepAlive(run)
+		return result
+	case <-ctx.Done():
+		// 실행을 실제로 멈춘 뒤에 반환해야 워커 슬롯과 고루틴이 새지 않는다
+		cause := context.Cause(ctx)
+		run.Interrupt(cause)
+		result := <-done
+		if !errors.Is(cause, errTimeLimit) {
+			return canceledResult(tc)
 		}
+		return result
+	}
+}
 
-		// 출력 비교
-		for i, expectedOutput := range tc.Output {
-			expected := strings.TrimSpace(expectedOutput)
-			actual := strings.TrimSpace(outputs[i])
-			if actual != expected {
-				result.Message = fmt.Sprintf("출력 불일치 (출력 #%d)\n예상: %s\n실제: %s",
-					i+1, expected, actual)
-				return
-			}
+// execute는 실행 고루틴에서 프로그램을 실행하고 판정을 내립니다.
+// check가 nil이면 Exact로 출력을 비교합니다.
+func execute(run Execution, env *Env, tc TestCase, check Checker) TestResult {
+	result := TestResult{
+		TestCaseID: tc.ID,
+		Passed:     false,
+	}
+	start := time.Now()
+	err := run.Run()
+	result.Elapsed = time.Since(start)
+	if flushErr := env.flush(); err == nil {
+		err = flushErr
+	}
+	result.Output = env.Output()
+
+	switch {
+	case err == nil:
+		if check == nil {
+			check = Exact
+		}
+		result.Verdict, result.Message = check.Check(tc, env.Output())
+		result.Passed = result.Verdict == Accepted
+	case errors.Is(err, errTimeLimit):
+		result.Verdict, result.Message = TimeLimitExceeded, "시간 초과"
+	case errors.Is(err, errStepLimit):
+		result.Verdict, result.Message = TimeLimitExceeded, "실행 단계 초과 (끝나지 않는 반복이 있는지 확인하세요)"
+	case errors.Is(err, errMemoryLimit):
+		result.Verdict, result.Message = MemoryLimitExceeded, "메모리 초과"
+	case errors.Is(err, errOutputLimit):
+		result.Verdict, result.Message = OutputLimitExceeded, "출력 초과"
+	default:
+		result.Verdict = RuntimeError
+		result.Error = err
+		if errors.Is(err, errNoInput) {
+			result.Message = "EOF"
 		}
+		var nodeErr NodeError
+		if errors.As(err, &nodeErr) {
+			result.Node = nodeErr.NodeID()
+		}
+	}
+	return result
+}
 
-		result.Passed = true
-		result.Message = "테스트 통과"
-	}()
+// compileFailure는 실행하기 전에 실패한 테스트케이스의 결과입니다
+func compileFailure(tc TestCase, err error) TestResult {
+	var violation *ConstraintError
+	if errors.As(err, &violation) {
+		return TestResult{TestCaseID: tc.ID, Verdict: ConstraintViolation, Error: err}
+	}
+	return TestResult{TestCaseID: tc.ID, Verdict: CompileError, Error: err}
+}
 
-	select {
-	case <-done:
-		return result
-	case <-time.After(j.timeout):
-		result.Message = "시간 초과"
-		return result
+// canceledResult는 채점 도중 요청이 취소된 테스트케이스의 결과입니다
+func canceledResult(tc TestCase) TestResult {
+	return TestResult{
+		TestCaseID: tc.ID,
+		Verdict:    RuntimeError,
+		Error:      errCanceled,
 	}
 }
@@ -0,0 +1,356 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitGoroutines는 고루틴 수가 before 이하로 돌아올 때까지 잠깐 기다립니다
+func waitGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("goroutines leaked: before=%d after=%d", before, after)
+	}
+}
+
+func TestTimeoutInterruptsVM(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	j := NewJudge(2, 10)
+	limits := Limits{Timeout: 100 * time.Millisecond}
+	cases := []TestCase{
+		{ID: 1, Output: []string{"x"}},
+		{ID: 2, Output: []string{"x"}},
+		{ID: 3, Output: []string{"x"}},
+	}
+	started := time.Now()
+	results, err := j.Run(context.Background(), Script("while (true) {}"), cases, limits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("judge took %v for 3 timed-out cases", elapsed)
+	}
+
+	for _, r := range results {
+		if r.Verdict != TimeLimitExceeded {
+			t.Errorf("case %d: verdict = %s, want %s", r.TestCaseID, r.Verdict, TimeLimitExceeded)
+		}
+	}
+	waitGoroutines(t, before)
+}
+
+func TestRunVerdicts(t *testing.T) {
+	j := NewJudge(4, 10)
+	limits := Limits{Timeout: 200 * time.Millisecond, OutputLines: 10}
+	sum := []TestCase{{ID: 1, Input: []string{"2", "3"}, Output: []string{"5"}}}
+
+	tests := []struct {
+		name string
+		code string
+		want Verdict
+	}{
+		{"accepted", "console.log(Number(prompt()) + Number(prompt()))", Accepted},
+		{"wrong answer", "console.log(6)", WrongAnswer},
+		{"trailing space", "console.log(' 5', '')", Accepted},
+		{"split lines", "console.log(5); console.log('')", PresentationError},
+		{"compile error", "console.log(", CompileError},
+		{"runtime error", "undefinedFunction()", RuntimeError},
+		{"input exhausted", "prompt(); prompt(); prompt()", RuntimeError},
+		{"time limit", "for (;;) {}", TimeLimitExceeded},
+		{"output limit", "for (;;) console.log(5)", OutputLimitExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := j.Run(context.Background(), Script(tt.code), sum, limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := results[0].Verdict; got != tt.want {
+				t.Fatalf("verdict = %s, want %s (message %q, error %v)",
+					got, tt.want, results[0].Message, results[0].Error)
+			}
+		})
+	}
+
+	// 참조 풀이로 정답을 만들 때 쓰도록 실제 출력을 돌려준다
+	results, err := j.Run(context.Background(), Script("console.log(6); console.log('a b')"), sum, limits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[0].Output; len(got) != 2 || got[0] != "6" || got[1] != "a b" {
+		t.Errorf("output = %q, want [6 a b]", got)
+	}
+	if !results[0].Verdict.Completed() {
+		t.Errorf("verdict %s should count as completed", results[0].Verdict)
+	}
+}
+
+func TestInputOutputBuiltins(t *testing.T) {
+	j := NewJudge(2, 10)
+	limits := Limits{Timeout: 200 * time.Millisecond, OutputLines: 3, OutputBytes: 100}
+	tc := []TestCase{{ID: 1, Input: []string{" 3 ", "a b", "x", "y"}}}
+
+	tests := []struct {
+		name    string
+		code    string
+		want    Verdict
+		output  []string
+		message string
+	}{
+		{"readers", "var n = readInt(); console.log(n + 1, readLine()); console.log(readAll().split('\\n').length, hasInput())",
+			WrongAnswer, []string{"4 a b", "2 false"}, ""},
+		{"print", "print(1, 2); print(''); print('-\\n'); print('x'); console.log('y'); print('z')",
+			WrongAnswer, []string{"1 2-", "xy", "z"}, ""},
+		{"read past end", "readAll(); console.log(hasInput()); readLine()", RuntimeError, []string{"false"}, "EOF"},
+		{"readInt of a word", "readLine(); readInt()", RuntimeError, nil, ""},
+		{"print past line limit", "console.log(1); console.log(2); console.log(3); print(4)", OutputLimitExceeded, nil, ""},
+		{"print past byte limit", "for (;;) print('x')", OutputLimitExceeded, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := j.Run(context.Background(), Script(tt.code), tc, limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := results[0]
+			if r.Verdict != tt.want || (tt.message != "" && r.Message != tt.message) {
+				t.Fatalf("verdict = %s %q, want %s %q (error %v)", r.Verdict, r.Message, tt.want, tt.message, r.Error)
+			}
+			if tt.output != nil && fmt.Sprint(r.Output) != fmt.Sprint(tt.output) {
+				t.Errorf("output = %q, want %q", r.Output, tt.output)
+			}
+		})
+	}
+}
+
+// TestRunConcurrent는 go test -race로 돌렸을 때 결과를 공유하는 곳이 없는지 확인합니다.
+// 끝나지 않는 케이스와 나머지 케이스를 두 제출로 나눠 동시에 채점하고, 나머지
+// 케이스에는 시간을 넉넉히 주어 CPU를 다투느라 느려져도 판정이 바뀌지 않게 합니다.
+func TestRunConcurrent(t *testing.T) {
+	before := runtime.NumGoroutine()
+	j := NewJudge(8, 10)
+	short := Limits{Timeout: 200 * time.Millisecond, OutputLines: 10}
+	long := Limits{Timeout: 30 * time.Second, OutputLines: 10}
+
+	// 입력에 따라 통과, 시간 초과, 출력 초과가 섞이도록 한다
+	var looping, finishing []TestCase
+	for i := 0; i < 32; i++ {
+		tc := TestCase{ID: i, Input: []string{fmt.Sprint(i)}, Output: []string{fmt.Sprint(i)}}
+		if i%3 == 1 {
+			looping = append(looping, tc)
+		} else {
+			finishing = append(finishing, tc)
+		}
+	}
+	code := `
+		var n = Number(prompt());
+		if (n % 3 === 1) { for (;;) {} }
+		if (n % 3 === 2) { for (;;) console.log(n); }
+		console.log(n);
+	`
+	for round := 0; round < 3; round++ {
+		jobs := make([]*Job, 2)
+		for k, run := range []struct {
+			cases  []TestCase
+			limits Limits
+		}{{looping, short}, {finishing, long}} {
+			job, err := j.Submit(context.Background(), Script(code), run.cases, run.limits, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jobs[k] = job
+		}
+		for _, job := range jobs {
+			for r := range job.Results {
+				want := []Verdict{Accepted, TimeLimitExceeded, OutputLimitExceeded}[r.TestCaseID%3]
+				if r.Verdict != want {
+					t.Errorf("case %d: verdict = %s, want %s", r.TestCaseID, r.Verdict, want)
+				}
+			}
+		}
+	}
+	waitGoroutines(t, before)
+}
+
+func TestRunCanceled(t *testing.T) {
+	before := runtime.NumGoroutine()
+	j := NewJudge(1, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cases := []TestCase{{ID: 1}, {ID: 2}}
+	started := time.Now()
+	results, err := j.Run(ctx, Script("for (;;) {}"), cases, Limits{Timeout: 10 * time.Second}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("canceled run took %v", elapsed)
+	}
+	for _, r := range results {
+		if r.Verdict != RuntimeError || r.Error != errCanceled {
+			t.Errorf("case %d: verdict = %s, error = %v, want canceled", r.TestCaseID, r.Verdict, r.Error)
+		}
+	}
+	waitGoroutines(t, before)
+}
+
+func TestQueueFull(t *testing.T) {
+	j := NewJudge(1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	running, err := j.Submit(ctx, Script("for (;;) {}"), []TestCase{{ID: 1}}, Limits{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-running.Started
+	if _, err := j.Run(context.Background(), Script("console.log(1)"), []TestCase{{ID: 2}}, Limits{}, nil); err != ErrQueueFull {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+
+	// 앞선 제출이 끝나면 다시 받아야 한다
+	cancel()
+	for range running.Results {
+	}
+	if _, err := j.Run(context.Background(), Script("console.log(1)"), []TestCase{{ID: 2}}, Limits{}, nil); err != nil {
+		t.Fatalf("err = %v after queue drained", err)
+	}
+}
+
+// 다른 제출이 메모리를 많이 쓰는 동안 함께 실행된 제출은 메모리 초과가 되면 안 된다
+func TestMemoryLimitIgnoresOtherRuns(t *testing.T) {
+	j := NewJudge(2, 10)
+	limits := Limits{Timeout: 5 * time.Second, MemoryBytes: 64 << 20}
+
+	hog := make(chan TestResult, 1)
+	go func() {
+		code := "var s = 'x'.repeat(1000), a = []; for (var i = 0; i < 100000; i++) a.push(s + i); for (;;) a.length"
+		results, _ := j.Run(context.Background(), Script(code), []TestCase{{ID: 1}}, limits, nil)
+		hog <- results[0]
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	code := "var t = Date.now(); while (Date.now() - t < 500) {} console.log('ok')"
+	results, err := j.Run(context.Background(), Script(code), []TestCase{{ID: 2, Output: []string{"ok"}}}, limits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results[0]; r.Verdict != Accepted {
+		t.Errorf("innocent run: verdict = %s (%v)", r.Verdict, r.Error)
+	}
+	// 메모리 제한이 있는 VM은 하나씩 실행되므로 메모리를 쓴 제출만 메모리 초과가 된다
+	if r := <-hog; r.Verdict != MemoryLimitExceeded {
+		t.Errorf("hog: verdict = %s, want %s", r.Verdict, MemoryLimitExceeded)
+	}
+}
+
+func TestMemoryLimitWithConcurrentRuns(t *testing.T) {
+	const runs = 4
+	j := NewJudge(runs, 10)
+	limits := Limits{Timeout: 30 * time.Second, MemoryBytes: 32 << 20}
+	code := "var s = 'x'.repeat(1000), a = []; for (var i = 0; ; i++) a.push(s + i)"
+	cases := make([]TestCase, runs)
+	for i := range cases {
+		cases[i] = TestCase{ID: i + 1}
+	}
+
+	runtime.GC()
+	start, _ := liveHeap()
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				peak = max(peak, heapObjectBytes())
+			}
+		}
+	}()
+	results, err := j.Run(context.Background(), Script(code), cases, limits, nil)
+	close(done)
+	<-sampled
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range results {
+		if r.Verdict != MemoryLimitExceeded {
+			t.Errorf("case %d: verdict = %s (%v), want %s", r.TestCaseID, r.Verdict, r.Error, MemoryLimitExceeded)
+		}
+	}
+	// 메모리 제한이 있는 VM은 하나씩 실행되므로 힙은 VM 하나의 제한과 아직 치우지
+	// 않은 쓰레기만큼만 늘어난다
+	if bound := start + 2*limits.MemoryBytes; peak > bound {
+		t.Errorf("peak heap = %d MB, want at most %d MB", peak>>20, bound>>20)
+	}
+}
+
+func TestMemoryLimitVerdictIgnoresOverlappingRuns(t *testing.T) {
+	j := NewJudge(2, 10)
+	limits := Limits{Timeout: 5 * time.Second, MemoryBytes: 64 << 20}
+	// 제한보다 조금 큰 120MB쯤을 쓰고 끝나는 프로그램과, 메모리를 거의 쓰지 않는 프로그램
+	hog := Script("var s = 'x'.repeat(1000), a = []; for (var i = 0; i < 120000; i++) a.push(s + i); console.log('done')")
+	small := Script("var t = Date.now(), a = []; while (Date.now() - t < 300) a.push(t); console.log('ok')")
+
+	for round := 0; round < 3; round++ {
+		var wg sync.WaitGroup
+		var hogResult, smallResult TestResult
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			results, _ := j.Run(context.Background(), hog, []TestCase{{ID: 1, Output: []string{"done"}}}, limits, nil)
+			hogResult = results[0]
+		}()
+		go func() {
+			defer wg.Done()
+			results, _ := j.Run(context.Background(), small, []TestCase{{ID: 2, Output: []string{"ok"}}}, limits, nil)
+			smallResult = results[0]
+		}()
+		wg.Wait()
+
+		if hogResult.Verdict != MemoryLimitExceeded {
+			t.Errorf("round %d: hog verdict = %s (%v), want %s", round, hogResult.Verdict, hogResult.Error, MemoryLimitExceeded)
+		}
+		if smallResult.Verdict != Accepted {
+			t.Errorf("round %d: small verdict = %s (%v), want %s", round, smallResult.Verdict, smallResult.Error, Accepted)
+		}
+	}
+}
+
+func TestMemoryLimitWaitsWithoutWorker(t *testing.T) {
+	j := NewJudge(1, 10)
+	// 다른 VM이 혼자 실행되는 중이라 차례를 기다리게 한다
+	release, err := heapGate.acquire(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan TestResult, 1)
+	go func() {
+		limits := Limits{Timeout: time.Second, MemoryBytes: 64 << 20}
+		results, _ := j.Run(context.Background(), Script("console.log('ok')"), []TestCase{{ID: 1, Output: []string{"ok"}}}, limits, nil)
+		done <- results[0]
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if n := len(j.workers); n != 0 {
+		t.Errorf("%d workers held while waiting for the gate", n)
+	}
+	release()
+	if r := <-done; r.Verdict != Accepted {
+		t.Errorf("verdict = %s (%v)", r.Verdict, r.Error)
+	}
+}
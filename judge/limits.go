@@ -0,0 +1,207 @@
+// judge/limits.go
+package judge
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limits는 테스트케이스 하나를 실행할 때 적용하는 자원 제한입니다.
+// 0인 항목은 제한하지 않습니다.
+type Limits struct {
+	Timeout     time.Duration
+	MemoryBytes uint64 // 실행 중 늘어난 힙 크기
+	OutputLines int    // 출력 줄 수
+	OutputBytes int    // 출력 전체 크기 (줄바꿈 포함)
+	Steps       int    // 실행할 수 있는 블록 수 (순서도 인터프리터)
+}
+
+// VM을 멈출 때 vm.Interrupt에 넘기는 사유
+var (
+	errTimeLimit   = errors.New("시간 초과")
+	errMemoryLimit = errors.New("메모리 초과")
+	errOutputLimit = errors.New("출력 초과")
+	errStepLimit   = errors.New("실행 단계 초과")
+	errCanceled    = errors.New("채점이 취소되었습니다")
+)
+
+// 메모리 감시 주기와, 메모리를 판정하려고 GC를 직접 부르는 최소 간격
+const (
+	memoryCheckInterval = 5 * time.Millisecond
+	forcedGCInterval    = 50 * time.Millisecond
+)
+
+// runGate는 VM을 실행할 차례를 정합니다. goja는 VM별 메모리 사용량을 알려주지
+// 않으므로, 메모리 제한이 있는 VM은 다른 VM 없이 혼자 실행해 프로세스 힙이 늘어난
+// 만큼을 그 VM의 사용량으로 봅니다. 그래서 메모리 판정은 함께 채점되는 다른 제출과
+// 상관없이 프로그램에만 달려 있습니다. 메모리 제한이 없는 VM끼리는 함께 실행합니다.
+type runGate struct {
+	mu        sync.Mutex
+	shared    int           // 실행 중인, 메모리 제한이 없는 VM 수
+	exclusive bool          // 메모리 제한이 있는 VM이 실행 중인지
+	waiting   int           // 차례를 기다리는, 메모리 제한이 있는 VM 수
+	changed   chan struct{} // 위 값이 바뀌면 닫는다
+}
+
+// heapGate는 프로세스 전체의 VM이 함께 쓰는 runGate입니다
+var heapGate runGate
+
+// finishedAt은 마지막으로 VM 하나가 끝났을 때까지 끝난 GC 횟수입니다
+var finishedAt atomic.Uint64
+
+// acquire는 VM을 실행할 차례를 기다립니다. exclusive면 실행 중인 VM이 모두 끝날
+// 때까지 기다리고, 아니면 메모리 제한이 있는 VM이 실행 중이거나 기다리는 동안
+// 기다립니다. ctx가 끝나면 errCanceled를 반환합니다. 실행이 끝나면 돌려받은
+// release를 불러야 합니다.
+func (g *runGate) acquire(ctx context.Context, exclusive bool) (release func(), err error) {
+	g.mu.Lock()
+	if exclusive {
+		g.waiting++
+	}
+	for {
+		if exclusive && !g.exclusive && g.shared == 0 {
+			g.waiting--
+			g.exclusive = true
+			break
+		}
+		if !exclusive && !g.exclusive && g.waiting == 0 {
+			g.shared++
+			break
+		}
+		if g.changed == nil {
+			g.changed = make(chan struct{})
+		}
+		changed := g.changed
+		g.mu.Unlock()
+		select {
+		case <-changed:
+			g.mu.Lock()
+		case <-ctx.Done():
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if exclusive {
+				g.waiting--
+				g.notify()
+			}
+			return nil, errCanceled
+		}
+	}
+	g.mu.Unlock()
+
+	return func() {
+		_, cycles := liveHeap()
+		finishedAt.Store(cycles)
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if exclusive {
+			g.exclusive = false
+		} else {
+			g.shared--
+		}
+		g.notify()
+	}, nil
+}
+
+// notify는 차례를 기다리는 쪽을 깨웁니다. g.mu를 잠근 채로 부릅니다.
+func (g *runGate) notify() {
+	if g.changed != nil {
+		close(g.changed)
+		g.changed = nil
+	}
+}
+
+// heapObjectBytes는 현재 힙 객체가 차지하는 바이트 수입니다. 아직 치우지 않은
+// 쓰레기도 들어갑니다. runtime.ReadMemStats와 달리 stop-the-world 없이 읽을 수
+// 있습니다.
+func heapObjectBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
+
+// liveHeap은 마지막 GC가 끝났을 때 살아 있던 힙 크기와 지금까지 끝난 GC 횟수입니다
+func liveHeap() (live, cycles uint64) {
+	samples := []metrics.Sample{{Name: "/gc/heap/live:bytes"}, {Name: "/gc/cycles/total:gc-cycles"}}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64(), samples[1].Value.Uint64()
+}
+
+// memoryBaseline은 VM을 시작하기 전의 살아 있는 힙 크기입니다. 마지막 GC 뒤에
+// 끝난 VM이 있으면 그 VM이 쓰던 메모리가 아직 살아 있는 것으로 잡혀 있으므로,
+// 그 양이 판정을 흐릴 만큼 크면 GC를 한 번 돌리고 잽니다.
+func memoryBaseline(limit uint64) uint64 {
+	live, cycles := liveHeap()
+	if cycles <= finishedAt.Load()+1 && live > limit/4 {
+		runtime.GC()
+		live, _ = liveHeap()
+	}
+	return live
+}
+
+// exceededAfterRun은 실행이 끝난 뒤 살아 있는 힙이 baseline보다 limit 넘게 늘어
+// 있는지 알려줍니다. 쓰레기까지 합쳐도 제한 안이면 GC를 돌리지 않고 false를
+// 반환합니다. VM이 아직 살아 있을 때 불러야 합니다.
+func exceededAfterRun(limit, baseline uint64) bool {
+	if heapObjectBytes() <= baseline+limit {
+		return false
+	}
+	runtime.GC()
+	live, _ := liveHeap()
+	return live > baseline && live-baseline > limit
+}
+
+// forceGC가 부른 GC가 도는 중인지와 마지막으로 부른 시각(UnixNano)
+var (
+	forcing  atomic.Bool
+	forcedAt atomic.Int64
+)
+
+// forceGC는 다음 GC를 기다리지 않고 힙을 바로 재도록 GC를 시작합니다. 감시는 GC가
+// 끝나기를 기다리지 않고 계속합니다. 한 번에 하나씩, forcedGCInterval에 한 번까지만
+// 시작합니다.
+func forceGC() {
+	if time.Now().UnixNano()-forcedAt.Load() < int64(forcedGCInterval) || !forcing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		runtime.GC()
+		forcedAt.Store(time.Now().UnixNano())
+		forcing.Store(false)
+	}()
+}
+
+// watchMemory는 stop이 닫힐 때까지 baseline보다 늘어난 힙 크기를 감시하다가
+// limit을 넘으면 onExceed를 한 번 호출합니다. VM은 heapGate로 혼자 실행되므로
+// VM을 시작한 뒤에 시작하고 끝난 GC가 잰 살아 있는 힙이 곧 이 VM의 사용량입니다.
+// GC가 따라가지 못할 만큼 힙 객체(쓰레기 포함)가 빨리 늘면 다음 GC를 기다리지
+// 않고 멈춥니다.
+func watchMemory(limit, baseline uint64, stop <-chan struct{}, onExceed func()) {
+	_, cycles := liveHeap()
+	trustAfter := cycles + 1 // 이 횟수보다 많이 끝난 뒤의 GC만 VM을 시작한 뒤에 시작했다
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			live, cycles := liveHeap()
+			baseline = min(baseline, live) // 시작할 때 남아 있던 쓰레기는 빼고 센다
+			heap := heapObjectBytes()
+			if cycles > trustAfter && live-baseline > limit || heap > 2*(baseline+limit)+limit {
+				onExceed()
+				return
+			}
+			// 쓰레기까지 합쳐 제한을 넘었으면 다음 GC를 기다리지 않고 바로 잰다
+			if heap > baseline+limit {
+				forceGC()
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// judge/run.go
+package judge
+
+import "context"
+
+// RunResult는 채점하지 않고 한 번 실행한 결과입니다. 끝까지 실행되었으면 Result의
+// 판정은 Accepted이고, 아니면 실행을 멈춘 사유의 판정입니다.
+type RunResult struct {
+	Result     TestResult
+	InputsRead int // 프로그램이 읽은 입력 줄 수
+}
+
+// anyOutput은 출력을 비교하지 않는 Checker입니다
+type anyOutput struct{}
+
+func (anyOutput) Check(TestCase, []string) (Verdict, string) {
+	return Accepted, "실행 완료"
+}
+
+// Execute는 tc의 입력과 실행 환경으로 프로그램을 한 번 실행합니다. 채점과 같은 제한,
+// 대기열, 워커를 쓰며 출력은 비교하지 않습니다. 대기열이 가득 차 있으면 ErrQueueFull을
+// 반환합니다.
+func (j *Judge) Execute(ctx context.Context, exec Executor, tc TestCase, limits Limits) (*RunResult, error) {
+	select {
+	case j.queue <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+	defer func() { <-j.queue }()
+
+	if err := exec.Compile(); err != nil {
+		return &RunResult{Result: compileFailure(tc, err)}, nil
+	}
+
+	release, err := j.acquire(ctx, limits.MemoryBytes > 0)
+	if err != nil {
+		return nil, ctx.Err()
+	}
+	defer release()
+
+	env := newEnv(tc, limits)
+	result := runSingleTest(ctx, exec, env, tc, limits, anyOutput{})
+	return &RunResult{Result: result, InputsRead: env.next}, nil
+}
@@ -0,0 +1,44 @@
+package judge
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExecute(t *testing.T) {
+	j := NewJudge(1, 1)
+	limits := Limits{Timeout: 200 * time.Millisecond, OutputLines: 10}
+
+	tests := []struct {
+		name   string
+		code   string
+		input  []string
+		want   Verdict
+		output []string
+		read   int
+	}{
+		{"runs", "var a = prompt(); console.log(a + '!')", []string{"hi", "unused"}, Accepted, []string{"hi!"}, 1},
+		{"partial output", "console.log(1); prompt(); prompt()", []string{"x"}, RuntimeError, []string{"1"}, 1},
+		{"compile error", "console.log(", nil, CompileError, nil, 0},
+		{"time limit", "for (;;) {}", nil, TimeLimitExceeded, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := j.Execute(context.Background(), Script(tt.code), TestCase{Input: tt.input}, limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run.Result.Verdict != tt.want {
+				t.Errorf("verdict = %s (%v), want %s", run.Result.Verdict, run.Result.Error, tt.want)
+			}
+			if len(tt.output) > 0 && !reflect.DeepEqual(run.Result.Output, tt.output) {
+				t.Errorf("output = %q, want %q", run.Result.Output, tt.output)
+			}
+			if run.InputsRead != tt.read {
+				t.Errorf("inputs read = %d, want %d", run.InputsRead, tt.read)
+			}
+		})
+	}
+}
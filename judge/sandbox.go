@@ -0,0 +1,478 @@
+// judge/sandbox.go
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+)
+
+// 학생 코드를 실행하는 VM의 제한
+const (
+	maxCallStack   = 1000    // 함수 호출 깊이
+	maxBuiltinSize = 1 << 22 // repeat, replace 등이 한 번에 만들 수 있는 크기, 내장 함수가 도는 배열 길이
+	maxCodeLength  = 1 << 17 // 코드 길이 (바이트)
+	maxCodeDepth   = 1000    // 괄호와 문법 트리가 겹칠 수 있는 깊이, 화살표 함수 수
+)
+
+// sandboxLocation은 Date가 지역 시간으로 쓰는 시간대입니다. goja의 Date는 지역
+// 시간에 time.Local을 쓰므로, newSandbox는 이 시간대로 계산하는 Date로 바꿔
+// 서버의 시간대 설정에 따라 채점 결과가 달라지지 않게 합니다.
+var sandboxLocation = time.FixedZone("KST", 9*60*60)
+
+// sandboxDateLayouts는 지역 시간을 문자열로 만드는 Date 메서드와 그 형식입니다.
+// goja의 형식을 그대로 씁니다.
+var sandboxDateLayouts = map[string]string{
+	"toString":           "Mon Jan 02 2006 15:04:05 GMT-0700 (MST)",
+	"toDateString":       "Mon Jan 02 2006",
+	"toTimeString":       "15:04:05 GMT-0700 (MST)",
+	"toLocaleString":     "01/02/2006, 15:04:05",
+	"toLocaleDateString": "01/02/2006",
+	"toLocaleTimeString": "15:04:05",
+}
+
+// sandboxGlobals는 학생 코드에 남겨 두는 전역 이름입니다. eval과 Function 생성자,
+// Proxy/Reflect, 비동기·바이너리 API, 깊게 중첩된 값을 Go 재귀로 처리하는 JSON은 뺍니다.
+var sandboxGlobals = map[string]bool{
+	"Object": true, "Array": true, "String": true, "Number": true, "Boolean": true,
+	"Symbol": true, "Math": true, "Date": true, "RegExp": true, "Map": true, "Set": true,
+	"Error": true, "TypeError": true, "RangeError": true, "SyntaxError": true,
+	"ReferenceError": true, "EvalError": true, "URIError": true,
+	"parseInt": true, "parseFloat": true, "isNaN": true, "isFinite": true,
+	"NaN": true, "Infinity": true, "undefined": true, "globalThis": true,
+	"encodeURI": true, "decodeURI": true, "encodeURIComponent": true, "decodeURIComponent": true,
+}
+
+// hardenProgram은 내장 객체를 잠그는 코드입니다. 지우기 전의 Reflect로 감싼
+// 함수를 만들고, Date와 정규식을 샌드박스용으로 바꾸고, 모든 함수 생성자를 막고,
+// 내장 프로토타입을 얼립니다. 얼린 뒤에도 자기 객체의 toString 등은 덮어쓸 수
+// 있습니다. host는 newSandbox가 넘기는 Go 값과 함수입니다.
+var hardenProgram = goja.MustCompile("sandbox", `(function (host) {
+	"use strict";
+	var apply = Reflect.apply, construct = Reflect.construct, defineProperty = Object.defineProperty,
+		getOwnPropertyDescriptor = Object.getOwnPropertyDescriptor,
+		getPrototypeOf = Object.getPrototypeOf, freeze = Object.freeze, limit = host.limit;
+
+	function method(target, name, fn) {
+		defineProperty(target, name, { value: fn, writable: true, configurable: true });
+	}
+
+	// 한 번에 큰 값을 만드는 내장 함수는 Interrupt 없이 오래 돌거나 메모리를 한꺼번에
+	// 잡으므로 크기를 먼저 확인한다. 감싼 함수는 JavaScript 호출이라 중첩 배열의
+	// toString/join 재귀도 호출 깊이 제한에 걸린다.
+	function tooLarge(name) {
+		return new RangeError(name + ": 만들려는 값이 너무 큽니다");
+	}
+	function guard(target, name, size, error) {
+		var original = target[name];
+		method(target, name, function () {
+			if (apply(size, this, arguments) > limit) {
+				throw (error || tooLarge)(name);
+			}
+			return apply(original, this, arguments);
+		});
+	}
+	function self() { return this.length; }
+	function first(n) { return n; }
+	function none() { return 0; }
+	function lengthOf(items) {
+		return items != null && typeof items.length === "number" ? items.length : 0;
+	}
+	guard(String.prototype, "repeat", function (n) { return String(this).length * n; });
+	guard(String.prototype, "padStart", first);
+	guard(String.prototype, "padEnd", first);
+	guard(Array.prototype, "toString", none);
+	guard(Array, "from", lengthOf, tooLong);
+	guard(Function.prototype, "apply", function (thisArg, args) { return lengthOf(args); }, tooLong);
+
+	// length만 크게 잡은 빈 배열(a.length = 5e7, a[1e9] = 1, {length: 5e7})도 length까지
+	// 하나씩 도는 메서드는 Interrupt 없이 오래 돈다. 전개 연산자, for-of, new Set(a)
+	// 등은 배열의 반복자를 쓰므로 반복자를 만드는 메서드도 막는다.
+	function tooLong(name) {
+		return new RangeError(name + ": 배열이 너무 깁니다");
+	}
+	["join", "fill", "indexOf", "lastIndexOf", "includes", "reverse", "sort", "copyWithin",
+		"forEach", "map", "filter", "some", "every", "reduce", "reduceRight", "find", "findIndex",
+		"findLast", "findLastIndex", "slice", "splice", "shift", "unshift", "toLocaleString",
+		"keys", "values", "entries"].forEach(function (name) {
+		if (typeof Array.prototype[name] === "function") {
+			guard(Array.prototype, name, self, tooLong);
+		}
+	});
+	method(Array.prototype, Symbol.iterator, Array.prototype.values);
+	guard(Array.prototype, "concat", function () {
+		var size = lengthOf(this);
+		for (var i = 0; i < arguments.length; i++) {
+			size += Array.isArray(arguments[i]) ? arguments[i].length : 1;
+		}
+		return size;
+	}, tooLong);
+	delete Array.prototype.flat;
+	delete Array.prototype.flatMap;
+
+	// goja의 Date는 지역 시간을 서버의 time.Local로 계산하므로, 지역 시간을 쓰는
+	// 생성자와 메서드를 host.offset(밀리초)만큼 떨어진 고정 시간대로 계산하게 바꾼다.
+	var NativeDate = Date, dateProto = NativeDate.prototype, offset = host.offset,
+		getTime = dateProto.getTime, setTime = dateProto.setTime, nativeParse = NativeDate.parse;
+	function parse(string) {
+		string = String(string);
+		return host.localTime(string, nativeParse(string));
+	}
+	var SandboxDate = function Date(year, month, day, hours, minutes, seconds, ms) {
+		if (new.target === undefined) {
+			return host.formatDate(NativeDate.now(), "toString");
+		}
+		var time;
+		if (arguments.length === 0) {
+			time = NativeDate.now();
+		} else if (arguments.length === 1) {
+			time = typeof year === "string" ? parse(year) : apply(getTime, construct(NativeDate, [year]), []);
+		} else {
+			time = apply(NativeDate.UTC, undefined, arguments) - offset;
+		}
+		return construct(NativeDate, [time], new.target);
+	};
+	defineProperty(SandboxDate, "prototype", { value: dateProto, writable: false });
+	method(SandboxDate, "now", NativeDate.now);
+	method(SandboxDate, "UTC", NativeDate.UTC);
+	method(SandboxDate, "parse", parse);
+	method(dateProto, "constructor", SandboxDate);
+	["FullYear", "Month", "Date", "Day", "Hours", "Minutes", "Seconds", "Milliseconds"].forEach(function (name) {
+		var get = dateProto["getUTC" + name], set = dateProto["setUTC" + name];
+		method(dateProto, "get" + name, function () {
+			return apply(get, new NativeDate(apply(getTime, this, []) + offset), []);
+		});
+		if (set) {
+			method(dateProto, "set" + name, function () {
+				var local = new NativeDate(apply(getTime, this, []) + offset);
+				apply(set, local, arguments);
+				return apply(setTime, this, [apply(getTime, local, []) - offset]);
+			});
+		}
+	});
+	method(dateProto, "getTimezoneOffset", function () {
+		var time = apply(getTime, this, []);
+		return time === time ? -offset / 60000 : NaN;
+	});
+	host.dateFormats.forEach(function (name) {
+		method(dateProto, name, function () {
+			return host.formatDate(apply(getTime, this, []), name);
+		});
+	});
+	globalThis.Date = SandboxDate;
+
+	// Go의 regexp로 실행할 수 없는 정규식은 실행 중에 Interrupt가 듣지 않는 역추적
+	// 엔진으로 실행되므로 만들지 못하게 한다. 리터럴은 compileSandboxed가 막고,
+	// 여기서는 실행 중에 문자열로 정규식을 만드는 길을 막는다.
+	var NativeRegExp = RegExp, regexpProto = NativeRegExp.prototype;
+	function checkRegExp(pattern, flags) {
+		var isRegExp = pattern instanceof NativeRegExp;
+		host.checkRegExp(
+			isRegExp ? pattern.source : pattern === undefined ? "" : String(pattern),
+			flags !== undefined ? String(flags) : isRegExp ? pattern.flags : "");
+	}
+	var SandboxRegExp = function RegExp(pattern, flags) {
+		checkRegExp(pattern, flags);
+		return construct(NativeRegExp, arguments, new.target || SandboxRegExp);
+	};
+	defineProperty(SandboxRegExp, "prototype", { value: regexpProto, writable: false });
+	defineProperty(SandboxRegExp, Symbol.species, getOwnPropertyDescriptor(NativeRegExp, Symbol.species));
+	method(regexpProto, "constructor", SandboxRegExp);
+	var compile = regexpProto.compile;
+	method(regexpProto, "compile", function (pattern, flags) {
+		checkRegExp(pattern, flags);
+		return apply(compile, this, arguments);
+	});
+	[["match", Symbol.match], ["matchAll", Symbol.matchAll], ["search", Symbol.search]].forEach(function (entry) {
+		var name = entry[0], symbol = entry[1], original = String.prototype[name];
+		method(String.prototype, name, function (regexp) {
+			if (regexp != null && Object(regexp)[symbol] == null) {
+				checkRegExp(regexp, name === "matchAll" ? "g" : undefined);
+			}
+			return apply(original, this, arguments);
+		});
+	});
+	globalThis.RegExp = SandboxRegExp;
+
+	// replace와 replaceAll은 찾은 수 × 바꿀 값의 길이만큼 큰 문자열을 한 번에 만들 수
+	// 있으므로(s.replace(/a/g, s)) 만들 문자열 크기의 상한을 먼저 잰다. $&와 $1은
+	// 찾은 부분을, $'와 그 짝은 찾은 곳마다 앞이나 뒤의 문자열 전체를 넣는다. 함수로
+	// 바꾸면 돌려준 값의 길이를 더해 가며 잰다.
+	var regexpMatch = regexpProto[Symbol.match], stringIndexOf = String.prototype.indexOf;
+	function replaceChecked(name, original, self, search, replaceValue, length, count) {
+		if (typeof replaceValue === "function") {
+			var replacer = replaceValue, total = length;
+			replaceValue = function () {
+				var result = String(apply(replacer, undefined, arguments));
+				if ((total += result.length) > limit) {
+					throw tooLarge(name);
+				}
+				return result;
+			};
+		} else {
+			replaceValue = String(replaceValue);
+			var size = length + count * replaceValue.length,
+				patterns = apply(regexpMatch, /\$[&\d<\x60']/g, [replaceValue]) || [];
+			for (var i = 0; i < patterns.length; i++) {
+				size += patterns[i] === "$\x60" || patterns[i] === "$'" ? count * length : length;
+			}
+			if (size > limit) {
+				throw tooLarge(name);
+			}
+		}
+		return apply(original, self, [search, replaceValue]);
+	}
+	var regexpReplace = regexpProto[Symbol.replace];
+	method(regexpProto, Symbol.replace, function (string, replaceValue) {
+		string = String(string);
+		var count = 1;
+		if (this.global) {
+			var matches = apply(regexpMatch, this, [string]);
+			count = matches ? matches.length : 0;
+		}
+		return replaceChecked("replace", regexpReplace, this, string, replaceValue, string.length, count);
+	});
+	["replace", "replaceAll"].forEach(function (name) {
+		var original = String.prototype[name];
+		method(String.prototype, name, function (search, replaceValue) {
+			// 정규식은 위의 Symbol.replace가 잰다
+			if (this == null || (search != null && Object(search)[Symbol.replace] != null)) {
+				return apply(original, this, arguments);
+			}
+			var string = String(this), count = 0;
+			search = String(search);
+			if (name === "replace") {
+				count = 1;
+			} else if (search === "") {
+				count = string.length + 1;
+			} else {
+				for (var i = apply(stringIndexOf, string, [search]); i >= 0; i = apply(stringIndexOf, string, [search, i + search.length])) {
+					count++;
+				}
+			}
+			return replaceChecked(name, original, string, search, replaceValue, string.length, count);
+		});
+	});
+
+	var blocked = function () {
+		throw new TypeError("eval과 Function 생성자는 사용할 수 없습니다");
+	};
+	[function () {}, function* () {}, async function () {}].forEach(function (f) {
+		defineProperty(getPrototypeOf(f), "constructor", { value: blocked, writable: false, configurable: false });
+	});
+
+	// 얼린 프로토타입의 속성은 상속받은 객체에도 대입할 수 없게 된다(엄격 모드에서는
+	// TypeError, 아니면 조용히 무시). 흔히 자기 객체에서 덮어쓰는 속성은 값 대신
+	// 접근자로 바꿔, 대입하면 받는 객체에 자기 속성을 만들게 한다.
+	function tame(target, name) {
+		var desc = getOwnPropertyDescriptor(target, name);
+		if (!desc || !("value" in desc) || !desc.configurable) {
+			return;
+		}
+		var value = desc.value;
+		defineProperty(target, name, {
+			get: function () { return value; },
+			set: function (v) {
+				if (this === target) {
+					throw new TypeError(String(name) + ": 내장 객체의 속성은 바꿀 수 없습니다");
+				}
+				defineProperty(this, name, { value: v, writable: true, enumerable: true, configurable: true });
+			},
+			enumerable: desc.enumerable,
+			configurable: false,
+		});
+	}
+	var overridden = ["constructor", "toString", "toLocaleString", "valueOf", "name", "message"];
+	Object.getOwnPropertyNames(Object.prototype).forEach(function (name) {
+		tame(Object.prototype, name);
+	});
+
+	var constructors = [Object, Array, String, Number, Boolean, Symbol, Date, RegExp, Map, Set,
+		Error, TypeError, RangeError, SyntaxError, ReferenceError, EvalError, URIError];
+	constructors.concat([Function]).forEach(function (c) {
+		overridden.forEach(function (name) {
+			tame(c.prototype, name);
+		});
+	});
+	constructors.forEach(function (c) {
+		freeze(c);
+		freeze(c.prototype);
+	});
+	freeze(Math);
+	[function () {}, function* () {}, async function () {}, function* () {}(),
+		[][Symbol.iterator](), getPrototypeOf([][Symbol.iterator]()), ""[Symbol.iterator](),
+		new Map()[Symbol.iterator](), new Set()[Symbol.iterator]()].forEach(function (o) {
+		freeze(getPrototypeOf(o));
+	});
+	freeze(blocked);
+})`, true)
+
+// newSandbox는 학생 코드를 실행할 VM을 만듭니다. 전역은 sandboxGlobals만 남기고,
+// 함수 호출 깊이를 제한해 끝없는 재귀가 서버의 스택을 넘치게 하지 않습니다.
+// Date의 지역 시간과 정규식 제한은 이 VM에만 적용되고 서버의 전역 설정은 그대로
+// 둡니다.
+func newSandbox() *goja.Runtime {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(maxCallStack)
+
+	_, offset := time.Unix(0, 0).In(sandboxLocation).Zone()
+	formats := make([]string, 0, len(sandboxDateLayouts))
+	for name := range sandboxDateLayouts {
+		formats = append(formats, name)
+	}
+	host := vm.NewObject()
+	host.Set("limit", maxBuiltinSize)
+	host.Set("offset", offset*1000)
+	host.Set("dateFormats", formats)
+	host.Set("formatDate", formatSandboxDate)
+	host.Set("localTime", func(s string, ms float64) float64 {
+		return sandboxLocalTime(s, ms, time.Local)
+	})
+	host.Set("checkRegExp", func(pattern, flags string) {
+		if err := checkRegExp(pattern, flags); err != nil {
+			panic(vm.NewTypeError(err.Error()))
+		}
+	})
+
+	harden, err := vm.RunProgram(hardenProgram)
+	if err != nil {
+		panic(err)
+	}
+	fn, _ := goja.AssertFunction(harden)
+	if _, err := fn(goja.Undefined(), host); err != nil {
+		panic(err)
+	}
+
+	global := vm.GlobalObject()
+	for _, name := range global.GetOwnPropertyNames() {
+		if !sandboxGlobals[name] {
+			global.Delete(name)
+		}
+	}
+	return vm
+}
+
+// formatSandboxDate는 Date의 시각 값 ms를 sandboxLocation의 지역 시간으로 method의
+// 형식에 맞춰 씁니다.
+func formatSandboxDate(ms float64, method string) string {
+	if math.IsNaN(ms) {
+		return "Invalid Date"
+	}
+	return time.UnixMilli(int64(ms)).In(sandboxLocation).Format(sandboxDateLayouts[method])
+}
+
+// Date.parse가 받는 문자열의 모양. isoDate에 맞으면 ISO 형식이고, 아니면 goja는
+// 시간대 이름이나 시각 뒤의 +hhmm이 없을 때 지역 시간으로 읽습니다.
+var (
+	isoDate      = regexp.MustCompile(`^([+-]\d{6}|\d{4})(-\d\d(-\d\d)?)?(T\d\d:\d\d(:\d\d([.,]\d+)?)?)?(Z|[+-]\d\d(?::?\d\d)?)?$`)
+	dateComment  = regexp.MustCompile(`\([^()]*\)`)
+	dateTimeZone = regexp.MustCompile(`(?i)(^|[^a-z])(gmt|utc?|z|[ecmp][sd]t|west?|cest|cet|eest|eet)|\d:\d\d.*[+-]\d`)
+)
+
+// sandboxLocalTime은 goja가 지역 시간을 local로 계산한 Date.parse(s)의 결과 ms를
+// 받아, s가 시간대 없이 시각을 적은 문자열이면 sandboxLocation의 시각으로 다시
+// 계산합니다. goja는 local에 time.Local을 씁니다.
+func sandboxLocalTime(s string, ms float64, local *time.Location) float64 {
+	if math.IsNaN(ms) {
+		return ms
+	}
+	if m := isoDate.FindStringSubmatch(s); m != nil {
+		// 날짜만 적은 ISO 형식은 UTC로 읽는다
+		if m[4] == "" || m[7] != "" {
+			return ms
+		}
+	} else {
+		for stripped := ""; stripped != s; {
+			stripped, s = s, dateComment.ReplaceAllString(s, " ")
+		}
+		if dateTimeZone.MatchString(s) {
+			return ms
+		}
+	}
+	wall := time.UnixMilli(int64(ms)).In(local)
+	year, month, day := wall.Date()
+	hour, minute, second := wall.Clock()
+	return float64(time.Date(year, month, day, hour, minute, second, wall.Nanosecond(), sandboxLocation).UnixMilli())
+}
+
+// checkRegExp는 정규식을 Go의 regexp로 실행할 수 있는지 확인합니다. 역참조나 전방·후방
+// 탐색이 있으면 goja는 역추적하는 regexp2로 실행하는데, 매칭 중에는 Interrupt가 듣지
+// 않아 역추적이 폭발하면 시간 제한이 지나도 워커가 멈추지 않습니다.
+func checkRegExp(pattern, flags string) error {
+	_, err := parser.TransformRegExp(pattern, strings.Contains(flags, "s"), strings.Contains(flags, "u"))
+	var incompatible parser.RegexpErrorIncompatible
+	if errors.As(err, &incompatible) {
+		return fmt.Errorf("/%s/: 역참조와 전방·후방 탐색은 사용할 수 없습니다", pattern)
+	}
+	return nil
+}
+
+// compileSandboxed는 샌드박스에서 실행할 코드를 컴파일합니다. BigInt 리터럴은
+// 거듭제곱 한 번으로 Interrupt 없이 CPU와 메모리를 다 쓸 수 있어 막고, 정규식
+// 리터럴은 checkRegExp로 확인합니다.
+func compileSandboxed(name, code string) (*goja.Program, error) {
+	if err := checkCodeSize(code); err != nil {
+		return nil, err
+	}
+	program, err := goja.Parse(name, code)
+	if err != nil {
+		return nil, err
+	}
+	var rejected error
+	walkScript(program, func(node ast.Node, depth int) int {
+		if rejected != nil {
+			return depth
+		}
+		// goja의 컴파일러는 식이 겹친 깊이의 제곱에 비례해 오래 걸린다
+		if depth++; depth > maxCodeDepth {
+			rejected = fmt.Errorf("코드가 너무 깊게 중첩되어 있습니다 (최대 %d단계)", maxCodeDepth)
+			return depth
+		}
+		switch n := node.(type) {
+		case *ast.NumberLiteral:
+			if _, ok := n.Value.(*big.Int); ok {
+				rejected = fmt.Errorf("%s: BigInt는 사용할 수 없습니다", n.Literal)
+			}
+		case *ast.RegExpLiteral:
+			rejected = checkRegExp(n.Pattern, n.Flags)
+		}
+		return depth
+	})
+	if rejected != nil {
+		return nil, rejected
+	}
+	return goja.CompileAST(program, false)
+}
+
+// checkCodeSize는 파싱하기 전에 코드 길이, 괄호가 겹친 깊이, 화살표 함수 수를
+// 제한합니다. goja의 파서는 괄호마다 재귀해 너무 깊으면 복구할 수 없는 스택
+// 오버플로로 프로세스가 죽고, 화살표 함수가 겹치면 그 수의 제곱만큼 오래 걸립니다.
+// 문자열과 주석 안의 괄호도 세지만, 길이 제한만으로도 스택이 넘치지는 않습니다.
+func checkCodeSize(code string) error {
+	if len(code) > maxCodeLength {
+		return fmt.Errorf("코드가 너무 깁니다 (%d바이트, 최대 %d바이트)", len(code), maxCodeLength)
+	}
+	if n := strings.Count(code, "=>"); n > maxCodeDepth {
+		return fmt.Errorf("화살표 함수가 너무 많습니다 (%d개, 최대 %d개)", n, maxCodeDepth)
+	}
+	depth := 0
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '(', '[', '{':
+			if depth++; depth > maxCodeDepth {
+				return fmt.Errorf("괄호가 너무 깊게 중첩되어 있습니다 (최대 %d단계)", maxCodeDepth)
+			}
+		case ')', ']', '}':
+			depth = max(depth-1, 0)
+		}
+	}
+	return nil
+}
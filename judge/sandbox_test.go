@@ -0,0 +1,242 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sandboxRun은 코드를 한 번 실행하고, 실행이 제한 시간 안에 멈췄는지 확인합니다.
+func sandboxRun(t *testing.T, code string) TestResult {
+	t.Helper()
+	j := NewJudge(1, 1)
+	// 시간 초과를 기대하는 경우는 없으므로, -race로 느려져도 판정이 바뀌지 않게 넉넉히 준다
+	limits := Limits{Timeout: 10 * time.Second, OutputLines: 10, MemoryBytes: 128 << 20}
+	started := time.Now()
+	results, err := j.Run(context.Background(), Script(code), []TestCase{{ID: 1, Output: []string{"ok"}}}, limits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > limits.Timeout+time.Second {
+		t.Errorf("run took %v", elapsed)
+	}
+	return results[0]
+}
+
+func TestSandboxEscapes(t *testing.T) {
+	escapes := []string{
+		"eval('1')",
+		"globalThis.eval('1')",
+		"Function('return this')()",
+		"(function () {}).constructor('return this')()",
+		"(function* () {}).constructor('yield 1')().next()",
+		"(async function () {}).constructor('return 1')()",
+		"console.log.constructor('return 1')()",
+		"prompt.constructor.constructor('return 1')()",
+		"new Proxy({}, {})",
+		"Reflect.ownKeys(globalThis)",
+		"new GoError('x')",
+		"new Uint8Array(8)",
+		"new Promise(function () {})",
+		"JSON.stringify({})",
+		"[[1]].flat()",
+	}
+	for _, code := range escapes {
+		t.Run(code, func(t *testing.T) {
+			if r := sandboxRun(t, code+"; console.log('ok')"); r.Verdict != RuntimeError {
+				t.Errorf("verdict = %s, want %s", r.Verdict, RuntimeError)
+			}
+		})
+	}
+}
+
+func TestSandboxFrozenPrototypes(t *testing.T) {
+	mutations := []string{
+		"Array.prototype.push = function () {}",
+		"Object.prototype.polluted = 1",
+		"String.prototype.trim = null",
+		"Object.defineProperty(Array.prototype, 'x', {value: 1})",
+		"Math.floor = Math.ceil",
+		"Object.getPrototypeOf(function () {}).call = null",
+		"Object.getPrototypeOf([][Symbol.iterator]()).next = null",
+		"Object.prototype.toString = null",
+		"Error.prototype.name = 'x'",
+		"Array.prototype.constructor = Object",
+	}
+	for _, code := range mutations {
+		t.Run(code, func(t *testing.T) {
+			// 엄격 모드에서는 얼린 객체를 바꾸면 TypeError가 나고, 아니면 조용히 무시된다
+			r := sandboxRun(t, "'use strict'; "+code+"; console.log('ok')")
+			if r.Verdict != RuntimeError {
+				t.Errorf("verdict = %s, want %s", r.Verdict, RuntimeError)
+			}
+		})
+	}
+
+	// 자기 객체와 배열은 평소처럼 쓸 수 있다
+	r := sandboxRun(t, `'use strict';
+var o = {a: 1}; o.b = 2;
+var a = [3, 1, 2]; a.sort(); a.push(4);
+var m = new Map(); m.set('k', a.join(''));
+console.log(m.get('k') === '1234' && o.a + o.b === 3 && [1, [2, 3]].toString() === '1,2,3' ? 'ok' : 'bad');`)
+	if r.Verdict != Accepted {
+		t.Errorf("ordinary code: verdict = %s (%v, %q)", r.Verdict, r.Error, r.Output)
+	}
+}
+
+func TestSandboxOverridesOnOwnObjects(t *testing.T) {
+	code := `var o = {}; o.toString = function () { return 'custom'; };
+function P() {} P.prototype.toString = function () { return 'p'; };
+var e = new Error('x'); e.name = 'MyError';
+var c = {}; c.constructor = P; c.valueOf = function () { return 41; };
+var d = new Date(0); d.toString = function () { return 'd'; };
+var f = function () {}; f.toString = function () { return 'f'; };
+console.log(String(o) === 'custom' && String(new P()) === 'p' && String(e) === 'MyError: x' &&
+	c.constructor === P && c + 1 === 42 && String(d) === 'd' && String(f) === 'f' &&
+	({}).toString() === '[object Object]' && !Object.prototype.hasOwnProperty.call({}, 'toString') ? 'ok' : 'bad');`
+	for _, mode := range []string{"", "'use strict';\n"} {
+		if r := sandboxRun(t, mode+code); r.Verdict != Accepted {
+			t.Errorf("%q: verdict = %s (%v, %q)", mode, r.Verdict, r.Error, r.Output)
+		}
+	}
+}
+
+func TestSandboxDoS(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want Verdict
+	}{
+		{"recursion", "function f() { return f() + 1; } f()", RuntimeError},
+		{"deep nested array to string", "var a = []; for (var i = 0; i < 100000; i++) a = [a]; console.log(a)", RuntimeError},
+		{"huge repeat", "'x'.repeat(Math.pow(2, 30))", RuntimeError},
+		{"huge padEnd", "''.padEnd(Math.pow(2, 30))", RuntimeError},
+		{"huge join", "new Array(Math.pow(2, 30)).join('x')", RuntimeError},
+		{"huge fill", "new Array(Math.pow(2, 30)).fill(0)", RuntimeError},
+		{"huge Array.from", "Array.from({length: Math.pow(2, 30)})", RuntimeError},
+		{"bigint", "console.log(String(10n ** 100000000n).length)", CompileError},
+		{"catastrophic regex", "for (;;) /^(a+)+(?=b)/.test('a'.repeat(30) + 'c')", CompileError},
+		{"backtracking regex from a string", "new RegExp('^(a+)+(?=b)').test('a'.repeat(30) + 'c')", RuntimeError},
+		{"backreference through match", "'aa'.match('(a)\\\\1')", RuntimeError},
+		{"nested quantifier", "for (var i = 0; i < 100; i++) /^(a+)+$/.test('a'.repeat(30) + 'c'); console.log('ok')", Accepted},
+		{"string doubling", "var s = 'x'; for (;;) s += s", MemoryLimitExceeded},
+		{"sparse indexOf", "var a = []; a.length = 5e7; a.indexOf(1)", RuntimeError},
+		{"sparse lastIndexOf", "var a = []; a.length = 5e7; a.lastIndexOf(1)", RuntimeError},
+		{"sparse includes", "var a = []; a.length = 5e7; a.includes(1)", RuntimeError},
+		{"sparse reverse", "var a = []; a.length = 5e7; a.reverse()", RuntimeError},
+		{"sparse sort", "var a = []; a.length = 5e7; a.sort()", RuntimeError},
+		{"sparse copyWithin", "var a = []; a.length = 5e7; a.copyWithin(0, 1)", RuntimeError},
+		{"max length indexOf", "var a = []; a.length = Math.pow(2, 32) - 1; a.indexOf(1)", RuntimeError},
+		{"far index forEach", "var a = []; a[1e9] = 1; a.forEach(function () {})", RuntimeError},
+		{"array-like slice", "Array.prototype.slice.call({length: 5e7})", RuntimeError},
+		{"sparse spread", "var a = []; a.length = 5e7; [...a]", RuntimeError},
+		{"sparse set", "var a = []; a.length = 5e7; new Set(a)", RuntimeError},
+		{"sparse apply", "var a = []; a.length = 5e7; Math.max.apply(null, a)", RuntimeError},
+		{"replace with itself", "var s = 'a'.repeat(Math.pow(2, 14)); s.replace(/a/g, s)", RuntimeError},
+		{"replace with the rest", "var s = 'a'.repeat(Math.pow(2, 14)); s.replace(/a/g, \"$'\")", RuntimeError},
+		{"replace with a function", "var s = 'a'.repeat(Math.pow(2, 14)); s.replace(/a/g, function () { return s; })", RuntimeError},
+		{"replaceAll with itself", "var s = 'a'.repeat(Math.pow(2, 14)); s.replaceAll('a', s)", RuntimeError},
+		{"replaceAll empty string", "var s = 'a'.repeat(Math.pow(2, 14)); s.replaceAll('', s)", RuntimeError},
+		{"huge nested brackets", strings.Repeat("[", 400000) + strings.Repeat("]", 400000), CompileError},
+		{"deep nested brackets", strings.Repeat("[", 2000) + strings.Repeat("]", 2000), CompileError},
+		{"long addition chain", "console.log(1" + strings.Repeat("+1", 20000) + ")", CompileError},
+		{"nested arrows", "var f = " + strings.Repeat("a=>", 5000) + "1", CompileError},
+		{"ordinary replace and spread", `var a = [3, 1, 2];
+var checks = ['a-b-c'.replace(/-/g, '+') === 'a+b+c', 'abc'.replace('b', '[$&]') === 'a[b]c',
+	'x1y2'.replace(/(\d)/g, function (d) { return d * 2; }) === 'x2y4', 'aXbX'.replaceAll('X', '$$') === 'a$b$',
+	Math.max.apply(null, [...a, ...new Set(a)]) === 3, a.concat([4]).indexOf(4) === 3];
+console.log(checks.indexOf(false) < 0 ? 'ok' : checks.join(' '))`, Accepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sandboxRun(t, tt.code)
+			if r.Verdict != tt.want {
+				t.Errorf("verdict = %s (%v), want %s", r.Verdict, r.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestSandboxFixedLocale(t *testing.T) {
+	r := sandboxRun(t, `console.log(new Date(0).getHours(), (1234.5).toLocaleString(), 'b'.localeCompare('a'))`)
+	if r.Verdict != WrongAnswer || len(r.Output) != 1 || !strings.HasPrefix(r.Output[0], "9 1234.5 1") {
+		t.Errorf("output = %q (%s %v)", r.Output, r.Verdict, r.Error)
+	}
+}
+
+func TestSandboxLocalTimeIgnoresServerZone(t *testing.T) {
+	newSandbox()
+	if time.Local == sandboxLocation {
+		t.Fatal("newSandbox changed time.Local")
+	}
+
+	// 서버의 시간대가 EST일 때 goja가 지역 시간으로 읽은 값을 KST로 다시 계산한다
+	est := time.FixedZone("EST", -5*60*60)
+	want := float64(time.Date(2023, 12, 31, 15, 30, 0, 0, time.UTC).UnixMilli())
+	parsed := []struct {
+		s    string
+		ms   float64
+		want float64
+	}{
+		{"Jan 1 2024 00:30", float64(time.Date(2024, 1, 1, 0, 30, 0, 0, est).UnixMilli()), want},
+		{"2024-01-01T00:30", float64(time.Date(2024, 1, 1, 0, 30, 0, 0, est).UnixMilli()), want},
+		{"2024-01-01T00:30Z", 1704069000000, 1704069000000},
+		{"Jan 1 2024 00:30 GMT+0100", 1704065400000, 1704065400000},
+		{"2024-01-01", 1704067200000, 1704067200000},
+	}
+	for _, p := range parsed {
+		if got := sandboxLocalTime(p.s, p.ms, est); got != p.want {
+			t.Errorf("sandboxLocalTime(%q) = %v, want %v", p.s, got, p.want)
+		}
+	}
+
+	r := sandboxRun(t, `var want = Date.UTC(2023, 11, 31, 15, 30);
+var d = new Date(2024, 0, 1, 0, 30);
+var checks = [
+	d.getTime() === want,
+	new Date('2024-01-01T00:30').getTime() === want,
+	Date.parse('Jan 1 2024 00:30') === want,
+	Date.parse('2024-01-01T00:30Z') === Date.UTC(2024, 0, 1, 0, 30),
+	Date.parse('Jan 1 2024 00:30 GMT+0100') === Date.UTC(2023, 11, 31, 23, 30),
+	new Date('2024-01-01').getTime() === Date.UTC(2024, 0, 1),
+	d.getFullYear() === 2024 && d.getMonth() === 0 && d.getDate() === 1 && d.getDay() === 1 && d.getHours() === 0,
+	d.getTimezoneOffset() === -540,
+	d.toString() === 'Mon Jan 01 2024 00:30:00 GMT+0900 (KST)',
+	d.toLocaleString() === '01/01/2024, 00:30:00',
+	String(d) === d.toString() && new Date(NaN).toString() === 'Invalid Date',
+	new Date(d.setHours(23)).getUTCHours() === 14 && d.getDate() === 1,
+	d instanceof Date && d.constructor === Date && typeof Date() === 'string',
+];
+console.log(checks.indexOf(false) < 0 ? 'ok' : checks.join(' '));`)
+	if r.Verdict != Accepted {
+		t.Errorf("output = %q (%s %v)", r.Output, r.Verdict, r.Error)
+	}
+}
+
+func TestSeededRandomAndFixedTime(t *testing.T) {
+	j := NewJudge(1, 1)
+	limits := Limits{Timeout: time.Second, OutputLines: 10, MemoryBytes: 256 << 20}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, sandboxLocation)
+	run := func(tc TestCase) []string {
+		t.Helper()
+		results, err := j.Run(context.Background(), Script(`console.log(Math.random(), Math.random(), new Date().getFullYear(), Date.now())`), []TestCase{tc}, limits, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results[0].Output
+	}
+
+	first := run(TestCase{ID: 1, Seed: 7, Now: now})
+	if again := run(TestCase{ID: 1, Seed: 7, Now: now}); len(first) != 1 || first[0] != again[0] {
+		t.Errorf("same seed: %q, %q", first, again)
+	}
+	if other := run(TestCase{ID: 1, Seed: 8, Now: now}); first[0] == other[0] {
+		t.Errorf("different seeds produced the same output %q", first)
+	}
+	want := fmt.Sprintf(" 2024 %d", now.UnixMilli())
+	if !strings.HasSuffix(first[0], want) {
+		t.Errorf("output = %q, want suffix %q", first[0], want)
+	}
+}
@@ -0,0 +1,41 @@
+// judge/score.go
+package judge
+
+import "math"
+
+// points는 테스트케이스의 배점입니다. 배점을 지정하지 않았으면 1점입니다.
+func (tc TestCase) points() int {
+	if tc.Points <= 0 {
+		return 1
+	}
+	return tc.Points
+}
+
+// TotalPoints는 테스트케이스 배점의 합입니다.
+func TotalPoints(cases []TestCase) int {
+	total := 0
+	for _, tc := range cases {
+		total += tc.points()
+	}
+	return total
+}
+
+// Score는 통과한 테스트케이스의 배점 합을 maxScore 만점으로 환산해 소수점 아래
+// 둘째 자리까지 돌려줍니다. 결과는 TestCaseID로 찾으므로 끝난 순서여도 됩니다.
+func Score(cases []TestCase, results []TestResult, maxScore float64) float64 {
+	total := TotalPoints(cases)
+	if total == 0 {
+		return 0
+	}
+	passed := make(map[int]bool, len(results))
+	for _, r := range results {
+		passed[r.TestCaseID] = r.Passed
+	}
+	earned := 0
+	for _, tc := range cases {
+		if passed[tc.ID] {
+			earned += tc.points()
+		}
+	}
+	return math.Round(float64(earned)*maxScore/float64(total)*100) / 100
+}
@@ -0,0 +1,29 @@
+package judge
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	cases := []TestCase{{ID: 1}, {ID: 2, Points: 2}, {ID: 3, Points: 3}}
+	if got := TotalPoints(cases); got != 6 {
+		t.Fatalf("TotalPoints = %d, want 6", got)
+	}
+
+	// 끝난 순서로 들어온 결과
+	results := []TestResult{{TestCaseID: 3, Passed: true}, {TestCaseID: 1, Passed: true}, {TestCaseID: 2}}
+	tests := []struct {
+		max  float64
+		want float64
+	}{
+		{6, 4},
+		{100, 66.67},
+		{10, 6.67},
+	}
+	for _, tt := range tests {
+		if got := Score(cases, results, tt.max); got != tt.want {
+			t.Errorf("Score(max %v) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+	if got := Score(nil, nil, 100); got != 0 {
+		t.Errorf("Score with no cases = %v, want 0", got)
+	}
+}
@@ -0,0 +1,139 @@
+// judge/script.go
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// maxSafeInteger는 JavaScript 숫자로 정확히 나타낼 수 있는 가장 큰 정수입니다
+const maxSafeInteger = 1<<53 - 1
+
+// script는 goja VM에서 실행하는 JavaScript 코드입니다
+type script struct {
+	code    string
+	program *goja.Program
+}
+
+// Script는 JavaScript 코드를 goja VM으로 실행하는 Executor를 만듭니다.
+// 테스트케이스마다 newSandbox로 새 VM을 만들고, 입력은 prompt(), 출력은 console.log로 합니다.
+// 입력 블록과 출력 블록이 쓸 수 있도록 readLine, readInt, readAll, hasInput, print도 둡니다.
+// Math.random과 Date는 Env의 난수와 시각을 씁니다.
+func Script(code string) Executor {
+	return &script{code: code}
+}
+
+func (s *script) Compile() error {
+	program, err := compileSandboxed("", s.code)
+	if err != nil {
+		return fmt.Errorf("문법 오류: %v", err)
+	}
+	s.program = program
+	return nil
+}
+
+func (s *script) Prepare(env *Env) Execution {
+	vm := newSandbox()
+	vm.SetRandSource(env.Random)
+	vm.SetTimeSource(env.Now)
+	return &scriptRun{vm: vm, program: s.program, env: env}
+}
+
+type scriptRun struct {
+	vm      *goja.Runtime
+	program *goja.Program
+	env     *Env
+}
+
+func (r *scriptRun) Interrupt(reason error) {
+	r.vm.Interrupt(reason)
+}
+
+func (r *scriptRun) Run() (err error) {
+	vm, env := r.vm, r.env
+	defer func() {
+		if p := recover(); p != nil {
+			if e, ok := p.(error); ok {
+				err = fmt.Errorf("런타임 에러: %w", e)
+			} else {
+				err = fmt.Errorf("런타임 에러: %v", p)
+			}
+		}
+	}()
+
+	// join은 console.log처럼 인자들을 공백으로 이어 붙입니다
+	join := func(args []goja.Value) string {
+		var output strings.Builder
+		for i, arg := range args {
+			if i > 0 {
+				output.WriteString(" ")
+			}
+			output.WriteString(arg.String()) // toString에서 난 예외는 그대로 런타임 에러가 된다
+		}
+		return output.String()
+	}
+	readLine := func(call goja.FunctionCall) goja.Value {
+		line, err := env.ReadLine()
+		if err != nil {
+			panic(err)
+		}
+		return vm.ToValue(line)
+	}
+
+	// console.log 함수 정의
+	console := map[string]interface{}{
+		"log": func(call goja.FunctionCall) goja.Value {
+			env.WriteLine(join(call.Arguments))
+			return goja.Undefined()
+		},
+	}
+	vm.Set("console", console)
+
+	// print는 줄을 바꾸지 않고 출력한다
+	vm.Set("print", func(call goja.FunctionCall) goja.Value {
+		env.Write(join(call.Arguments))
+		return goja.Undefined()
+	})
+
+	// prompt와 readLine은 입력 한 줄을 문자열로 돌려준다
+	vm.Set("prompt", readLine)
+	vm.Set("readLine", readLine)
+
+	// readInt는 입력 한 줄을 정수로 읽는다
+	vm.Set("readInt", func(call goja.FunctionCall) goja.Value {
+		line, err := env.ReadLine()
+		if err != nil {
+			panic(err)
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(line), 10, 64)
+		if err != nil || n > maxSafeInteger || n < -maxSafeInteger {
+			panic(vm.NewTypeError(fmt.Sprintf("readInt: %q는 정수가 아닙니다", line)))
+		}
+		return vm.ToValue(n)
+	})
+
+	// readAll은 남은 입력을 모두 읽어 줄바꿈으로 이은 문자열을 돌려준다
+	vm.Set("readAll", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(strings.Join(env.ReadAll(), "\n"))
+	})
+
+	vm.Set("hasInput", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(env.HasInput())
+	})
+
+	// 코드 실행
+	if _, err := vm.RunProgram(r.program); err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			if reason, ok := interrupted.Value().(error); ok {
+				return reason
+			}
+		}
+		return fmt.Errorf("실행 오류: %v", err)
+	}
+	return nil
+}
@@ -1,17 +1,80 @@
 // judge/testcase.go
 package judge
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
 // TestCase 구조체 정의
 type TestCase struct {
 	ID     int
 	Input  []string
 	Output []string
+	Points int // 배점, 0이면 1점
+	// 실행 환경. 같은 테스트케이스는 Math.random과 Date가 항상 같은 값을 돌려줍니다.
+	Seed int64     // Math.random 시드, 0이면 ID를 시드로 씀
+	Now  time.Time // Date가 돌려줄 현재 시각, 비어 있으면 실제 시각
 }
 
 // TestResult 구조체 정의
 type TestResult struct {
 	TestCaseID int
+	Verdict    Verdict
 	Passed     bool
 	Message    string
 	Error      error
+	Node       string   // 실행 오류가 난 순서도 블록 ID
+	Output     []string // 프로그램이 실제로 출력한 줄들
+	Elapsed    time.Duration
+}
+
+// testResultJSON은 제출 기록 저장과 API 응답에 쓰이는 TestResult의 JSON 형태입니다
+type testResultJSON struct {
+	TestCaseID int      `json:"testCaseId"`
+	Verdict    Verdict  `json:"verdict"`
+	Passed     bool     `json:"passed"`
+	Message    string   `json:"message"`
+	Error      string   `json:"error,omitempty"`
+	Node       string   `json:"node,omitempty"`
+	Output     []string `json:"output,omitempty"`
+	ElapsedMs  float64  `json:"elapsedMs"`
+}
+
+func (r TestResult) MarshalJSON() ([]byte, error) {
+	out := testResultJSON{
+		TestCaseID: r.TestCaseID,
+		Verdict:    r.Verdict,
+		Passed:     r.Passed,
+		Message:    r.Message,
+		Node:       r.Node,
+		Output:     r.Output,
+		ElapsedMs:  float64(r.Elapsed) / float64(time.Millisecond),
+	}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
+func (r *TestResult) UnmarshalJSON(data []byte) error {
+	var in testResultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*r = TestResult{
+		TestCaseID: in.TestCaseID,
+		Verdict:    in.Verdict,
+		Passed:     in.Passed,
+		Message:    in.Message,
+		Node:       in.Node,
+		Output:     in.Output,
+		Elapsed:    time.Duration(in.ElapsedMs * float64(time.Millisecond)),
+	}
+	if in.Error != "" {
+		r.Error = errors.New(in.Error)
+	}
+	return nil
 }
@@ -0,0 +1,88 @@
+// judge/trace.go
+package judge
+
+import "context"
+
+// 실행 기록 사건의 종류
+const (
+	TraceStep   = "step"   // 블록 하나를 실행함
+	TraceInput  = "input"  // 입력 한 줄을 읽음
+	TraceOutput = "output" // 출력 한 줄을 씀
+)
+
+// TraceEvent는 실행 기록의 사건 하나입니다. step 사건에는 실행한 블록과 실행한
+// 뒤의 변수 값(문자열은 따옴표로 감싼 모양)이, input/output 사건에는 읽거나 쓴
+// 한 줄이 담깁니다.
+type TraceEvent struct {
+	Kind  string            `json:"kind"`
+	Node  string            `json:"node,omitempty"`
+	Vars  map[string]string `json:"vars,omitempty"`
+	Value string            `json:"value,omitempty"`
+}
+
+// Trace는 테스트케이스 하나를 실행한 기록입니다. 사건이 Limit개를 넘으면 더
+// 기록하지 않고 Truncated를 true로 둡니다.
+type Trace struct {
+	Events    []TraceEvent `json:"events"`
+	Truncated bool         `json:"truncated"`
+	Result    TestResult   `json:"result"`
+	limit     int
+}
+
+func (t *Trace) add(ev TraceEvent) {
+	if len(t.Events) >= t.limit {
+		t.Truncated = true
+		return
+	}
+	t.Events = append(t.Events, ev)
+}
+
+// Tracing은 실행 기록을 남기는 중인지 알려줍니다. 실행기는 기록하지 않을 때
+// 변수 값을 문자열로 만드는 비용을 아낄 수 있습니다. 기록이 Limit개를 채운 뒤에는
+// Truncated를 true로 두고 false를 반환합니다.
+func (e *Env) Tracing() bool {
+	if e.trace == nil {
+		return false
+	}
+	if len(e.trace.Events) >= e.trace.limit {
+		e.trace.Truncated = true
+		return false
+	}
+	return true
+}
+
+// RecordStep은 블록 하나를 실행했음을 기록합니다. 기록 중이 아니면 아무것도 하지 않습니다.
+func (e *Env) RecordStep(node string, vars map[string]string) {
+	if e.trace != nil {
+		e.trace.add(TraceEvent{Kind: TraceStep, Node: node, Vars: vars})
+	}
+}
+
+// Trace는 테스트케이스 하나를 실행하면서 방문한 블록, 블록마다의 변수 값, 입출력을
+// 기록합니다. 실행 기록을 남기지 않는 실행기(JavaScript 코드)는 입출력만 기록됩니다.
+// 채점과 같은 대기열과 워커를 쓰며, 대기열이 가득 차 있으면 ErrQueueFull을 반환합니다.
+func (j *Judge) Trace(ctx context.Context, exec Executor, tc TestCase, limits Limits, check Checker, maxEvents int) (*Trace, error) {
+	select {
+	case j.queue <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+	defer func() { <-j.queue }()
+
+	trace := &Trace{Events: []TraceEvent{}, limit: maxEvents}
+	if err := exec.Compile(); err != nil {
+		trace.Result = compileFailure(tc, err)
+		return trace, nil
+	}
+
+	release, err := j.acquire(ctx, limits.MemoryBytes > 0)
+	if err != nil {
+		return nil, ctx.Err()
+	}
+	defer release()
+
+	env := newEnv(tc, limits)
+	env.trace = trace
+	trace.Result = runSingleTest(ctx, exec, env, tc, limits, check)
+	return trace, nil
+}
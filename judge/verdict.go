@@ -0,0 +1,40 @@
+// judge/verdict.go
+package judge
+
+// Verdict는 테스트케이스 하나(또는 제출 전체)의 채점 판정입니다.
+// 프론트엔드가 메시지 문자열 대신 이 값으로 결과를 표시합니다.
+type Verdict string
+
+const (
+	Accepted            Verdict = "AC"  // 정답
+	WrongAnswer         Verdict = "WA"  // 오답
+	TimeLimitExceeded   Verdict = "TLE" // 시간 초과
+	RuntimeError        Verdict = "RE"  // 실행 중 오류
+	CompileError        Verdict = "CE"  // 문법 오류
+	MemoryLimitExceeded Verdict = "MLE" // 메모리 초과
+	OutputLimitExceeded Verdict = "OLE" // 출력 초과
+	PresentationError   Verdict = "PE"  // 공백/줄바꿈만 다른 출력
+	ConstraintViolation Verdict = "CV"  // 문제의 구조 제한 위반 (실행하지 않음)
+	JudgeError          Verdict = "JE"  // 선생님이 작성한 check 함수의 오류
+)
+
+// Overall은 테스트케이스 순서대로 처음 실패한 판정을 제출 전체의 판정으로 돌려줍니다.
+// 모두 통과했으면 Accepted입니다.
+func Overall(results []TestResult) Verdict {
+	for _, r := range results {
+		if r.Verdict != Accepted {
+			return r.Verdict
+		}
+	}
+	return Accepted
+}
+
+// Completed는 프로그램이 제한에 걸리거나 오류 없이 끝까지 실행된 판정인지 알려줍니다.
+// 이때 TestResult.Output이 프로그램의 전체 출력입니다.
+func (v Verdict) Completed() bool {
+	switch v {
+	case Accepted, WrongAnswer, PresentationError, JudgeError:
+		return true
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"Flow-Chart-Block-Coding-Backend/config"
 	"Flow-Chart-Block-Coding-Backend/db"
 	"Flow-Chart-Block-Coding-Backend/handlers"
+	"Flow-Chart-Block-Coding-Backend/judge"
 	"log"
 	"time"
 
@@ -22,6 +23,12 @@ func main() {
 	// JWT 시크릿 키 설정
 	handlers.SetJWTSecret(cfg.GetJWTSecret())
 
+	// 문제별 채점 제한의 기본값과 최댓값
+	handlers.SetJudgeLimits(cfg.GetJudgeLimits(), cfg.GetJudgeMaxLimits())
+
+	// 채점 서비스 (모든 요청이 워커와 대기열을 공유)
+	judgeService := judge.NewJudge(cfg.GetJudgeWorkers(), cfg.GetJudgeQueueSize())
+
 	// 데이터베이스 연결
 	database, err := db.InitDB(cfg.GetDSN())
 	if err != nil {
@@ -46,22 +53,30 @@ func main() {
 	{
 		// 핸들러 초기화
 		problemHandler := handlers.NewProblemHandler(database)
+		testcaseHandler := handlers.NewTestcaseHandler(database, judgeService)
 		classHandler := handlers.NewClassHandler(database)
 		handler := handlers.NewUserHandler(database)
-		solvedHandler := handlers.SolvedHandler(database)
+		solvedHandler := handlers.SolvedHandler(database, judgeService)
 
 		// Solve 그룹
 		solve := api.Group("/solve")
 		{
-			solve.POST("", solvedHandler)
+			solve.POST("", handlers.LimitCodeRequest(), handlers.OptionalAuthMiddleware(), solvedHandler)
 			solve.GET("/user/:username", handlers.GetUserSolvedProblems(database))
 			solve.GET("/problem/:problem_id", handlers.GetProblemSolvedUsers(database))
+			solve.GET("/user/:username/problem/:problem_id", handlers.GetUserSubmissions(database))
+			solve.GET("/:submission_id", handlers.OptionalAuthMiddleware(), handlers.GetSubmission(database))
+			solve.GET("/:submission_id/events", handlers.OptionalAuthMiddleware(), handlers.SubmissionEvents(database))
+			solve.GET("/:submission_id/trace/:case_id", handlers.AuthMiddleware(), handlers.GetSubmissionTrace(database, judgeService))
 		}
 
+		// 채점 없이 실행해 보기
+		api.POST("/run", handlers.LimitCodeRequest(), handlers.RunHandler(database, judgeService))
+
 		// Problems 그룹
 		problems := api.Group("/problems")
 		{
-			problems.GET("/:id", problemHandler.GetProblem)
+			problems.GET("/:id", handlers.OptionalAuthMiddleware(), problemHandler.GetProblem)
 
 			// 보호된 라우트
 			protected := problems.Group("")
@@ -71,6 +86,20 @@ func main() {
 				protected.PUT("/:id", problemHandler.UpdateProblem)
 				protected.DELETE("/:id", problemHandler.DeleteProblem)
 				protected.GET("", problemHandler.ListProblems)
+				protected.GET("/:id/scores", problemHandler.ListScores)
+			}
+
+			// 테스트케이스 (문제를 만든 클래스만 접근)
+			testcases := problems.Group("/:id/testcases")
+			testcases.Use(handlers.AuthMiddleware())
+			{
+				testcases.GET("", testcaseHandler.ListTestcases)
+				testcases.POST("", testcaseHandler.CreateTestcase)
+				testcases.POST("/verify", testcaseHandler.VerifyTestcases)
+				testcases.POST("/generate", testcaseHandler.GenerateTestcaseOutputs)
+				testcases.GET("/:testcase_id", testcaseHandler.GetTestcase)
+				testcases.PUT("/:testcase_id", testcaseHandler.UpdateTestcase)
+				testcases.DELETE("/:testcase_id", testcaseHandler.DeleteTestcase)
 			}
 		}
 
@@ -78,7 +107,7 @@ func main() {
 		classes := api.Group("/classes")
 		{
 			classes.POST("", classHandler.CreateClass) // 로그인/회원가입용
-			classes.GET("/number/:classnum", classHandler.GetClassByClassnum)
+			classes.GET("/number/:classnum", handlers.OptionalAuthMiddleware(), classHandler.GetClassByClassnum)
 
 			// 보호된 라우트
 			protected := classes.Group("")
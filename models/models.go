@@ -1,6 +1,11 @@
 package models
 
 import (
+	"Flow-Chart-Block-Coding-Backend/flowchart"
+	"Flow-Chart-Block-Coding-Backend/judge"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -12,12 +17,41 @@ type Class struct {
 }
 
 type Problem struct {
-	ID             uint   `gorm:"primaryKey"`
-	Title          string `gorm:"type:varchar(200)"`
-	Content        string `gorm:"type:varchar(500)"`
-	TestcaseInput  string `gorm:"type:varchar(100)"`
-	TestcaseOutput string `gorm:"type:varchar(100)"`
-	ClassID        uint   `gorm:"foreignKey:ClassID;references:ID"`
+	ID              uint               `gorm:"primaryKey"`
+	Title           string             `gorm:"type:varchar(200)"`
+	Content         string             `gorm:"type:varchar(500)"`
+	TestcaseInput   string             `gorm:"type:varchar(100)"`
+	TestcaseOutput  string             `gorm:"type:varchar(100)"`
+	TimeLimit       int                // 테스트케이스 하나의 실행 시간, ms 단위, 0이면 기본값
+	MemoryLimit     int                // MB 단위, 0이면 기본값 (기본 설정은 제한 없음, 지정하면 테스트케이스를 하나씩 실행)
+	OutputLineLimit int                // 출력 줄 수, 0이면 기본값
+	OutputByteLimit int                // 출력 바이트 수, 0이면 기본값
+	MaxScore        int                // 만점, 0이면 테스트케이스 배점의 합
+	RandomSeed      int64              // Math.random 시드, 0이면 테스트케이스마다 정해진 값
+	FixedTime       *time.Time         // Date가 돌려줄 현재 시각, 없으면 실제 시각
+	Constraints     *judge.Constraints `gorm:"serializer:json;type:text"` // 풀이 구조 제한 ("반복문 사용" 등), 없으면 nil
+	Checker         *judge.CheckerSpec `gorm:"serializer:json;type:text"` // 채점 방식, 없으면 exact
+	// 선생님이 작성한 참조 풀이. 순서도가 있으면 순서도를, 없으면 코드를 실행해
+	// 테스트케이스의 정답을 만들거나 확인합니다.
+	ReferenceCode      string           `gorm:"type:mediumtext"`
+	ReferenceFlowchart *flowchart.Graph `gorm:"serializer:json;type:mediumtext"`
+	// 제출마다 새로 만드는 무작위 테스트케이스. 정답은 참조 풀이로 만듭니다.
+	Generator *judge.GeneratorSpec `gorm:"serializer:json;type:text"`
+	ClassID   uint                 `gorm:"foreignKey:ClassID;references:ID"`
+	Testcases []Testcase           `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Testcase struct {
+	ID         uint       `gorm:"primaryKey"`
+	ProblemID  uint       `gorm:"index"`
+	Name       string     `gorm:"type:varchar(100)"`
+	Position   int        // 채점 순서 (오름차순)
+	IsSample   bool       // 문제 설명에 공개되는 예제 여부 (false면 채점 전용)
+	Points     int        // 배점, 0이면 1점
+	RandomSeed int64      // 문제의 Math.random 시드 대신 쓸 시드 (0이면 문제 설정)
+	FixedTime  *time.Time // 문제의 고정 시각 대신 쓸 시각 (없으면 문제 설정)
+	Input      []string   `gorm:"serializer:json;type:text"` // 한 줄씩 prompt()로 전달
+	Output     []string   `gorm:"serializer:json;type:text"` // console.log 한 줄씩 비교
 }
 
 type User struct {
@@ -33,3 +67,93 @@ type Solved struct {
 	UserName  string    `gorm:"type:varchar(50)"`
 	SolvedAt  time.Time `gorm:"autoCreateTime"`
 }
+
+// BestScore는 사용자가 문제에서 받은 가장 높은 점수입니다. 더 높은 점수를 받은
+// 제출이 있을 때만 갱신합니다.
+type BestScore struct {
+	ID           uint   `gorm:"primaryKey"`
+	ProblemID    uint   `gorm:"uniqueIndex:idx_best_score_problem_user"`
+	UserID       uint   `gorm:"uniqueIndex:idx_best_score_problem_user"`
+	UserName     string `gorm:"type:varchar(50)"`
+	Score        float64
+	MaxScore     float64
+	SubmissionID uint      // 이 점수를 받은 제출
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+type Submission struct {
+	ID          uint               `gorm:"primaryKey"`
+	ProblemID   uint               `gorm:"index"`
+	UserID      uint               `gorm:"index"`
+	UserName    string             `gorm:"type:varchar(50)"`
+	Code        string             `gorm:"type:mediumtext"`
+	Flowchart   *flowchart.Graph   `gorm:"serializer:json;type:mediumtext"`     // 학생이 만든 순서도 (있는 경우)
+	Engine      string             `gorm:"type:varchar(20);default:javascript"` // 채점에 쓴 실행기
+	Status      string             `gorm:"type:varchar(10);default:finished"`   // queued → running → finished
+	CaseCount   int                // 채점할 테스트케이스 수
+	Seed        int64              // 무작위 테스트케이스를 만든 시드, 생성기가 없으면 0
+	Verdict     judge.Verdict      `gorm:"type:varchar(10)"` // 처음 실패한 테스트케이스의 판정
+	Score       float64            // 통과한 테스트케이스 배점으로 매긴 점수
+	MaxScore    float64            // 이 제출의 만점
+	Results     []judge.TestResult `gorm:"serializer:json;type:mediumtext"` // 테스트케이스별 채점 결과 (채점 중에는 끝난 순서)
+	SubmittedAt time.Time          `gorm:"autoCreateTime"`
+}
+
+// 제출 채점 상태
+const (
+	SubmissionQueued   = "queued"   // 대기열에서 워커를 기다리는 중
+	SubmissionRunning  = "running"  // 테스트케이스 실행 중
+	SubmissionFinished = "finished" // 채점 완료, Verdict에 최종 판정
+	SubmissionCanceled = "canceled" // 서버 재시작이나 가득 찬 대기열로 채점이 중단됨
+)
+
+// 제출을 실행하는 방법
+const (
+	EngineJavaScript  = "javascript"  // Code를 goja VM에서 실행
+	EngineInterpreter = "interpreter" // Flowchart를 블록 단위로 직접 실행
+)
+
+// ParseLegacyTestcases는 예전 형식('/'로 케이스 구분, 공백으로 입력 구분)의
+// TestcaseInput/TestcaseOutput 문자열을 Testcase 목록으로 변환합니다.
+// 첫 번째 케이스만 예제로 공개하고 나머지는 비공개로 둡니다.
+func ParseLegacyTestcases(input, output string) []Testcase {
+	inputGroups := strings.Split(strings.TrimSpace(input), "/")
+	outputs := strings.Split(strings.TrimSpace(output), "/")
+
+	var testcases []Testcase
+	for i, group := range inputGroups {
+		if i >= len(outputs) {
+			break
+		}
+		inputs := strings.Fields(group)
+		if len(inputs) == 0 {
+			continue
+		}
+		testcases = append(testcases, Testcase{
+			Name:     fmt.Sprintf("#%d", i+1),
+			Position: i,
+			IsSample: len(testcases) == 0,
+			Input:    inputs,
+			Output:   []string{strings.TrimSpace(outputs[i])},
+		})
+	}
+	return testcases
+}
+
+// IsLegacyTestcases는 testcases가 예전 형식의 문자열에서 만든 그대로인지
+// 알려줍니다. 테스트케이스 API로 추가하거나 고친 케이스가 있으면 false입니다.
+func IsLegacyTestcases(testcases []Testcase, input, output string) bool {
+	legacy := ParseLegacyTestcases(input, output)
+	if len(testcases) != len(legacy) {
+		return false
+	}
+	for i, tc := range testcases {
+		want := legacy[i]
+		if tc.Name != want.Name || tc.Position != want.Position || tc.IsSample != want.IsSample ||
+			tc.Points != 0 || tc.RandomSeed != 0 || tc.FixedTime != nil ||
+			!slices.Equal(tc.Input, want.Input) || !slices.Equal(tc.Output, want.Output) {
+			return false
+		}
+	}
+	return true
+}